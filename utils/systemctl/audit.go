@@ -0,0 +1,91 @@
+package systemctl
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	OperationStart   = "start"
+	OperationStop    = "stop"
+	OperationEnable  = "enable"
+	OperationDisable = "disable"
+	OperationMask    = "mask"
+	OperationUnmask  = "unmask"
+	OperationKill    = "kill"
+	OperationInstall = "install"
+
+	OperationUninstall = "uninstall"
+	OperationRevert    = "revert"
+
+	OperationFreeze = "freeze"
+	OperationThaw   = "thaw"
+
+	OperationRestart         = "restart"
+	OperationReload          = "reload"
+	OperationReloadOrRestart = "reload-or-restart"
+)
+
+// AuditEntry records one mutating operation performed on a unit. Err is nil if the operation succeeded.
+type AuditEntry struct {
+	Time      time.Time
+	Operation string
+	Unit      string
+	Err       error
+}
+
+var auditLog struct {
+	sync.Mutex
+
+	entries []AuditEntry
+	next    int
+	full    bool
+}
+
+// EnableAuditLog starts recording mutating operations in memory, keeping only the latest size entries.
+// Any previously recorded entries are discarded. A size of zero or less disables the audit log again.
+func EnableAuditLog(size int) {
+	auditLog.Lock()
+	defer auditLog.Unlock()
+
+	auditLog.entries = nil
+	if size > 0 {
+		auditLog.entries = make([]AuditEntry, size)
+	}
+
+	auditLog.next = 0
+	auditLog.full = false
+}
+
+// AuditLog returns the recorded operations, oldest first. It is empty unless EnableAuditLog was called.
+func AuditLog() []AuditEntry {
+	auditLog.Lock()
+	defer auditLog.Unlock()
+
+	if auditLog.full {
+		return append(append([]AuditEntry{}, auditLog.entries[auditLog.next:]...), auditLog.entries[:auditLog.next]...)
+	}
+
+	return append([]AuditEntry{}, auditLog.entries[:auditLog.next]...)
+}
+
+func audit(operation, unit string, err error) {
+	auditLog.Lock()
+	defer auditLog.Unlock()
+
+	if len(auditLog.entries) == 0 {
+		return
+	}
+
+	auditLog.entries[auditLog.next] = AuditEntry{
+		Time:      time.Now(),
+		Operation: operation,
+		Unit:      unit,
+		Err:       err,
+	}
+
+	auditLog.next = (auditLog.next + 1) % len(auditLog.entries)
+	if auditLog.next == 0 {
+		auditLog.full = true
+	}
+}
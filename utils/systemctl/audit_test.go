@@ -0,0 +1,60 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+		"smbd.service":   {"ActiveState": "active", "UnitFileState": "enabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, StartService("casaos.service"))
+	assert.Empty(t, AuditLog())
+
+	EnableAuditLog(3)
+	t.Cleanup(func() { EnableAuditLog(0) })
+
+	assert.NoError(t, StopService("casaos.service"))
+	assert.NoError(t, StartService("casaos.service"))
+	assert.ErrorIs(t, StartService("nonexistent.service"), errNoSuchUnit)
+
+	entries := AuditLog()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, []string{OperationStop, OperationStart, OperationStart}, auditOperations(entries))
+	assert.Equal(t, "casaos.service", entries[0].Unit)
+	assert.NoError(t, entries[0].Err)
+	assert.Equal(t, "nonexistent.service", entries[2].Unit)
+	assert.ErrorIs(t, entries[2].Err, errNoSuchUnit)
+	assert.False(t, entries[0].Time.After(entries[2].Time))
+
+	// the oldest entries are evicted once the log is full
+	assert.NoError(t, MaskAndStopService("smbd.service"))
+	assert.NoError(t, UnmaskService("smbd.service"))
+
+	entries = AuditLog()
+	assert.Equal(t, []string{OperationStart, OperationMask, OperationUnmask}, auditOperations(entries))
+	assert.Equal(t, []string{"nonexistent.service", "smbd.service", "smbd.service"}, auditUnits(entries))
+}
+
+func auditOperations(entries []AuditEntry) []string {
+	operations := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		operations = append(operations, entry.Operation)
+	}
+
+	return operations
+}
+
+func auditUnits(entries []AuditEntry) []string {
+	units := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		units = append(units, entry.Unit)
+	}
+
+	return units
+}
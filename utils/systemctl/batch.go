@@ -0,0 +1,111 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ServiceErrors maps the name of each unit an operation failed for to the reason.
+type ServiceErrors map[string]error
+
+func (e ServiceErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		// a JobError already names its unit
+		var jobErr *JobError
+		if errors.As(e[name], &jobErr) && jobErr.Unit == name {
+			messages = append(messages, e[name].Error())
+			continue
+		}
+
+		messages = append(messages, name+": "+e[name].Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap allows errors.Is and errors.As to match the error of any unit.
+func (e ServiceErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// StartServices starts the units together, e.g. the units of an app bundle, by enqueuing all jobs at once and
+// waiting for all of them to finish. If any fail, a ServiceErrors with the failed units is returned.
+func StartServices(names []string) error {
+	return StartServicesContext(context.Background(), names)
+}
+
+// StartServicesContext is like StartServices, but with a caller-supplied context.
+func StartServicesContext(ctx context.Context, names []string) error {
+	return runJobs(ctx, OperationStart, startUnit, names)
+}
+
+// StopServices stops the units together, like StartServices.
+func StopServices(names []string) error {
+	return StopServicesContext(context.Background(), names)
+}
+
+// StopServicesContext is like StopServices, but with a caller-supplied context.
+func StopServicesContext(ctx context.Context, names []string) error {
+	return runJobs(ctx, OperationStop, stopUnit, names)
+}
+
+// runJobs runs the job for each unit concurrently and collects the errors.
+func runJobs(ctx context.Context, operation string, job func(context.Context, connection, string) error, names []string) error {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = ServiceErrors{}
+	)
+
+	for _, name := range names {
+		wg.Add(1)
+
+		go func(name string) {
+			defer wg.Done()
+
+			err := job(ctx, conn, name)
+			audit(operation, name, err)
+
+			if err != nil {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}(name)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package systemctl
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartServices(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos-app-web.service": {"ActiveState": "inactive"},
+		"casaos-app-db.service":  {"ActiveState": "inactive"},
+		"casaos-app-job.service": {"ActiveState": "inactive"},
+	})
+	conn.results["casaos-app-job.service"] = ResultDependency
+	conn.use(t)
+
+	err := StartServices([]string{"casaos-app-db.service", "casaos-app-web.service", "casaos-app-job.service", "missing.service"})
+	assert.ErrorIs(t, err, ErrorDependency)
+	assert.ErrorIs(t, err, errNoSuchUnit)
+
+	var errs ServiceErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs["casaos-app-job.service"], ErrorDependency)
+	assert.ErrorIs(t, errs["missing.service"], errNoSuchUnit)
+	assert.Equal(t, "casaos-app-job.service: "+ErrorDependency.Error()+"; missing.service: "+errNoSuchUnit.Error(), err.Error())
+
+	assert.Equal(t, "active", conn.units["casaos-app-db.service"]["ActiveState"])
+	assert.Equal(t, "active", conn.units["casaos-app-web.service"]["ActiveState"])
+
+	conn.calls = nil
+
+	assert.NoError(t, StopServices([]string{"casaos-app-db.service", "casaos-app-web.service"}))
+
+	sort.Strings(conn.calls)
+	assert.Equal(t, []string{"stop casaos-app-db.service", "stop casaos-app-web.service"}, conn.calls)
+}
@@ -0,0 +1,116 @@
+package systemctl
+
+import (
+	"context"
+	"path/filepath"
+)
+
+const defaultTarget = "default.target"
+
+// BootService is an enabled unit together with the targets that pull it in at boot.
+type BootService struct {
+	Name     string
+	WantedBy []string
+
+	// WillStart is true if one of the WantedBy targets is reached when booting into the default target.
+	WillStart bool
+}
+
+// ListBootServices returns the enabled units, annotated with their `WantedBy=` targets and whether booting
+// into the default target will start them.
+func ListBootServices() ([]BootService, error) {
+	return ListBootServicesContext(context.Background())
+}
+
+// ListBootServicesContext is like ListBootServices, but with a caller-supplied context.
+func ListBootServicesContext(ctx context.Context) ([]BootService, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	files, err := conn.ListUnitFilesByPatternsContext(ctx, []string{"enabled", "enabled-runtime"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies := func(unit string) ([]string, error) {
+		properties, err := conn.GetUnitPropertiesContext(ctx, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		wants, _ := properties["Wants"].([]string)
+		requires, _ := properties["Requires"].([]string)
+
+		return append(wants, requires...), nil
+	}
+
+	// default.target is an alias, so also resolve the target it stands for
+	properties, err := conn.GetUnitPropertiesContext(ctx, defaultTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	reachable := map[string]bool{defaultTarget: true}
+	if id, ok := properties["Id"].(string); ok {
+		reachable[id] = true
+	}
+
+	units, err := expandUnit(defaultTarget, maxExpandDepth, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, unit := range units {
+		reachable[unit] = true
+	}
+
+	services := make([]BootService, 0, len(files))
+
+	for _, file := range files {
+		serviceName := filepath.Base(file.Path)
+
+		// templates cannot be loaded themselves, e.g. `getty@.service`, which is enabled on stock Debian
+		if isTemplate(serviceName) {
+			continue
+		}
+
+		properties, err := conn.GetUnitPropertiesContext(ctx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		wantedBy, _ := properties["WantedBy"].([]string)
+
+		services = append(services, bootService(serviceName, wantedBy, reachable))
+	}
+
+	return services, nil
+}
+
+func bootService(name string, wantedBy []string, reachable map[string]bool) BootService {
+	service := BootService{
+		Name:     name,
+		WantedBy: wantedBy,
+	}
+
+	if service.WantedBy == nil {
+		service.WantedBy = []string{}
+	}
+
+	for _, target := range service.WantedBy {
+		if reachable[target] {
+			service.WillStart = true
+			break
+		}
+	}
+
+	return service
+}
@@ -0,0 +1,44 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootService(t *testing.T) {
+	reachable := map[string]bool{
+		"default.target":    true,
+		"multi-user.target": true,
+		"basic.target":      true,
+	}
+
+	assert.Equal(t, BootService{Name: "casaos.service", WantedBy: []string{"multi-user.target"}, WillStart: true},
+		bootService("casaos.service", []string{"multi-user.target"}, reachable))
+
+	assert.Equal(t, BootService{Name: "casaos-kiosk.service", WantedBy: []string{"graphical.target"}, WillStart: false},
+		bootService("casaos-kiosk.service", []string{"graphical.target"}, reachable))
+
+	assert.Equal(t, BootService{Name: "casaos-apps.service", WantedBy: []string{"graphical.target", "multi-user.target"}, WillStart: true},
+		bootService("casaos-apps.service", []string{"graphical.target", "multi-user.target"}, reachable))
+
+	assert.Equal(t, BootService{Name: "casaos.socket", WantedBy: []string{}, WillStart: false},
+		bootService("casaos.socket", nil, reachable))
+}
+
+func TestListBootServices(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"default.target":       {"Id": "multi-user.target", "Wants": []string{"casaos.service"}, "Requires": []string{"basic.target"}, "UnitFileState": "static"},
+		"basic.target":         {"Wants": []string{}, "Requires": []string{}, "UnitFileState": "static"},
+		"casaos.service":       {"WantedBy": []string{"multi-user.target"}, "Wants": []string{}, "Requires": []string{}, "UnitFileState": "enabled"},
+		"casaos-kiosk.service": {"WantedBy": []string{"graphical.target"}, "UnitFileState": "enabled"},
+		"getty@.service":       {"UnitFileState": "enabled"},
+	}).use(t)
+
+	services, err := ListBootServices()
+	assert.NoError(t, err)
+	assert.Equal(t, []BootService{
+		{Name: "casaos-kiosk.service", WantedBy: []string{"graphical.target"}, WillStart: false},
+		{Name: "casaos.service", WantedBy: []string{"multi-user.target"}, WillStart: true},
+	}, services)
+}
@@ -0,0 +1,67 @@
+package systemctl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBootTimes(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{})
+	conn.manager = map[string]interface{}{
+		"FirmwareTimestampMonotonic":  uint64(5000000),
+		"LoaderTimestampMonotonic":    uint64(2000000),
+		"InitRDTimestampMonotonic":    uint64(1500000),
+		"UserspaceTimestampMonotonic": uint64(4000000),
+		"FinishTimestampMonotonic":    uint64(19000000),
+	}
+	conn.use(t)
+
+	times, err := GetBootTimes()
+	assert.NoError(t, err)
+	assert.Equal(t, BootTimes{
+		Firmware:  3 * time.Second,
+		Loader:    2 * time.Second,
+		Kernel:    1500 * time.Millisecond,
+		InitRD:    2500 * time.Millisecond,
+		Userspace: 15 * time.Second,
+	}, times)
+	assert.Equal(t, 24*time.Second, times.Total())
+
+	conn.manager["InitRDTimestampMonotonic"] = uint64(0)
+
+	times, err = GetBootTimes()
+	assert.NoError(t, err)
+	assert.Equal(t, 4*time.Second, times.Kernel)
+	assert.Equal(t, time.Duration(0), times.InitRD)
+
+	conn.manager["FinishTimestampMonotonic"] = uint64(0)
+
+	_, err = GetBootTimes()
+	assert.ErrorIs(t, err, ErrorBootNotFinished)
+}
+
+func TestGetServiceStartupTimes(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {
+			"InactiveExitTimestampMonotonic": uint64(5000000),
+			"ActiveEnterTimestampMonotonic":  uint64(5200000),
+		},
+		"docker.service": {
+			"InactiveExitTimestampMonotonic": uint64(4000000),
+			"ActiveEnterTimestampMonotonic":  uint64(9000000),
+		},
+		"never.service": {
+			"InactiveExitTimestampMonotonic": uint64(0),
+			"ActiveEnterTimestampMonotonic":  uint64(0),
+		},
+	}).use(t)
+
+	times, err := GetServiceStartupTimes()
+	assert.NoError(t, err)
+	assert.Equal(t, []ServiceStartupTime{
+		{Name: "docker.service", Duration: 5 * time.Second},
+		{Name: "casaos.service", Duration: 200 * time.Millisecond},
+	}, times)
+}
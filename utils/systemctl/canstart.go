@@ -0,0 +1,159 @@
+package systemctl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// StartBlockedNotFound means there is no unit file for the unit.
+	StartBlockedNotFound = "not-found"
+
+	// StartBlockedMasked means the unit is masked, see UnmaskService.
+	StartBlockedMasked = "masked"
+
+	// StartBlockedLoadError means systemd could not load the unit file, e.g. because of a syntax error.
+	StartBlockedLoadError = "load-error"
+
+	// StartBlockedRefuseManualStart means the unit only starts as a dependency of another, by `RefuseManualStart=`.
+	StartBlockedRefuseManualStart = "refuse-manual-start"
+
+	// StartBlockedCondition means a `Condition*=` of the unit failed the last time it was checked, so starting it
+	// would be skipped.
+	StartBlockedCondition = "condition-failed"
+
+	// StartBlockedDependency means a unit required by `Requires=`, `Requisite=` or `BindsTo=` is missing or masked.
+	StartBlockedDependency = "missing-dependency"
+)
+
+// StartCheck is the outcome of CanStartService. If CanStart is false, Reason is one of the StartBlocked*
+// constants and Message explains it for display.
+type StartCheck struct {
+	CanStart bool
+	Reason   string
+	Message  string
+}
+
+// CanStartService checks whether starting the unit can succeed, based on its unit file, mask, `Condition*=`
+// results and required dependencies, so a UI can explain why it cannot be started rather than fail after the fact.
+// A unit that passes may still fail to start, e.g. if its process exits.
+func CanStartService(name string) (StartCheck, error) {
+	return CanStartServiceContext(context.Background(), name)
+}
+
+// CanStartServiceContext is like CanStartService, but with a caller-supplied context.
+func CanStartServiceContext(ctx context.Context, name string) (StartCheck, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return StartCheck{}, err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return StartCheck{}, err
+	}
+
+	if check, ok, err := loadStateCheck(name, properties); err != nil || !ok {
+		return check, err
+	}
+
+	if properties["RefuseManualStart"] == true {
+		return StartCheck{Reason: StartBlockedRefuseManualStart, Message: name + " can only be started as a dependency of another unit"}, nil
+	}
+
+	if check, ok := conditionCheck(name, properties); !ok {
+		return check, nil
+	}
+
+	for _, property := range []string{"Requires", "Requisite", "BindsTo"} {
+		dependencies, _ := properties[property].([]string)
+		for _, dependency := range dependencies {
+			state, err := getStringProperty(ctx, conn, dependency, "LoadState")
+			if err != nil {
+				return StartCheck{}, err
+			}
+
+			if state != "loaded" {
+				return StartCheck{
+					Reason:  StartBlockedDependency,
+					Message: fmt.Sprintf("%s requires %s, which is %s", name, dependency, state),
+				}, nil
+			}
+		}
+	}
+
+	return StartCheck{CanStart: true}, nil
+}
+
+func loadStateCheck(name string, properties map[string]interface{}) (StartCheck, bool, error) {
+	state, err := mapStringProperty(properties, "LoadState")
+	if err != nil {
+		return StartCheck{}, false, err
+	}
+
+	switch state {
+	case "loaded":
+		return StartCheck{}, true, nil
+	case "not-found":
+		return StartCheck{Reason: StartBlockedNotFound, Message: name + " does not exist"}, false, nil
+	case "masked":
+		return StartCheck{Reason: StartBlockedMasked, Message: name + " is masked"}, false, nil
+	default:
+		message := name + " failed to load"
+
+		// `LoadError` has the D-Bus signature `(ss)`, the error name and message
+		if loadError, ok := properties["LoadError"].([]interface{}); ok && len(loadError) == 2 {
+			if detail, _ := loadError[1].(string); detail != "" {
+				message += ": " + detail
+			}
+		}
+
+		return StartCheck{Reason: StartBlockedLoadError, Message: message}, false, nil
+	}
+}
+
+// conditionCheck reports the conditions that failed the last time they were checked.
+func conditionCheck(name string, properties map[string]interface{}) (StartCheck, bool) {
+	if timestamp, _ := properties["ConditionTimestamp"].(uint64); timestamp == 0 || properties["ConditionResult"] != false {
+		return StartCheck{}, true
+	}
+
+	failed := make([]string, 0)
+
+	// `Conditions` has the D-Bus signature `a(sbbsi)`: type, trigger, negate, parameter and state, which is
+	// negative for a failed condition
+	conditions, _ := properties["Conditions"].([][]interface{})
+	for _, condition := range conditions {
+		if len(condition) < 5 {
+			continue
+		}
+
+		if state, _ := condition[4].(int32); state >= 0 {
+			continue
+		}
+
+		kind, _ := condition[0].(string)
+		negate, _ := condition[2].(bool)
+		parameter, _ := condition[3].(string)
+
+		if negate {
+			parameter = "!" + parameter
+		}
+
+		failed = append(failed, kind+"="+parameter)
+	}
+
+	message := name + " does not meet its start conditions"
+	if len(failed) > 0 {
+		message += ": " + strings.Join(failed, ", ")
+	}
+
+	return StartCheck{Reason: StartBlockedCondition, Message: message}, false
+}
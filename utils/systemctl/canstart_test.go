@@ -0,0 +1,54 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanStartService(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service":         {"LoadState": "loaded", "Requires": []string{"casaos-gateway.service"}},
+		"casaos-gateway.service": {"LoadState": "loaded"},
+		"missing.service":        {"LoadState": "not-found"},
+		"masked.service":         {"LoadState": "masked"},
+		"broken.service":         {"LoadState": "bad-setting", "LoadError": []interface{}{"org.freedesktop.systemd1.BadUnitSetting", "Unit has a bad unit file setting."}},
+		"shutdown.service":       {"LoadState": "loaded", "RefuseManualStart": true},
+		"raspi.service": {
+			"LoadState":          "loaded",
+			"ConditionResult":    false,
+			"ConditionTimestamp": uint64(1700000000000000),
+			"Conditions": [][]interface{}{
+				{"ConditionPathExists", false, false, "/boot/config.txt", int32(-1)},
+				{"ConditionVirtualization", false, true, "container", int32(1)},
+			},
+		},
+		"fresh.service":  {"LoadState": "loaded", "ConditionResult": false, "ConditionTimestamp": uint64(0)},
+		"orphan.service": {"LoadState": "loaded", "BindsTo": []string{"missing.service"}},
+	}).use(t)
+
+	for name, expected := range map[string]StartCheck{
+		"casaos.service":   {CanStart: true},
+		"fresh.service":    {CanStart: true},
+		"missing.service":  {Reason: StartBlockedNotFound, Message: "missing.service does not exist"},
+		"masked.service":   {Reason: StartBlockedMasked, Message: "masked.service is masked"},
+		"broken.service":   {Reason: StartBlockedLoadError, Message: "broken.service failed to load: Unit has a bad unit file setting."},
+		"shutdown.service": {Reason: StartBlockedRefuseManualStart, Message: "shutdown.service can only be started as a dependency of another unit"},
+		"raspi.service":    {Reason: StartBlockedCondition, Message: "raspi.service does not meet its start conditions: ConditionPathExists=/boot/config.txt"},
+		"orphan.service":   {Reason: StartBlockedDependency, Message: "orphan.service requires missing.service, which is not-found"},
+	} {
+		check, err := CanStartService(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, check, name)
+	}
+}
+
+func TestCanStartServiceWithoutLoadState(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive"},
+	}).use(t)
+
+	// a missing `LoadState` is not mistaken for a unit that failed to load
+	_, err := CanStartService("casaos.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
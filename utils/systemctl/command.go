@@ -0,0 +1,50 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+var ErrorUnknownServiceCommand = errors.New("unknown service command")
+
+// serviceCommands are the commands RunServiceCommand accepts. Unlike OpenRC init scripts, systemd units cannot
+// define commands of their own.
+var serviceCommands = map[string]func(ctx context.Context, name string) error{
+	"start": func(ctx context.Context, name string) error {
+		return StartServiceContext(ctx, name)
+	},
+	"stop":              StopServiceContext,
+	"restart":           RestartServiceContext,
+	"reload":            ReloadServiceContext,
+	"reload-or-restart": ReloadOrRestartServiceContext,
+	"reset-failed":      ResetFailedServiceContext,
+}
+
+// ServiceCommands returns the commands RunServiceCommand accepts, sorted.
+func ServiceCommands() []string {
+	commands := make([]string, 0, len(serviceCommands))
+	for command := range serviceCommands {
+		commands = append(commands, command)
+	}
+
+	sort.Strings(commands)
+
+	return commands
+}
+
+// RunServiceCommand runs the command, e.g. `reload`, on the unit, for callers offering commands by name.
+// ErrorUnknownServiceCommand is returned for commands not listed by ServiceCommands.
+func RunServiceCommand(name, command string) error {
+	return RunServiceCommandContext(context.Background(), name, command)
+}
+
+// RunServiceCommandContext is like RunServiceCommand, but with a caller-supplied context.
+func RunServiceCommandContext(ctx context.Context, name, command string) error {
+	run, ok := serviceCommands[command]
+	if !ok {
+		return ErrorUnknownServiceCommand
+	}
+
+	return run(ctx, name)
+}
@@ -0,0 +1,21 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunServiceCommand(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"nginx.service": {"ActiveState": "active", "CanReload": true},
+	})
+	conn.use(t)
+
+	assert.Equal(t, []string{"reload", "reload-or-restart", "reset-failed", "restart", "start", "stop"}, ServiceCommands())
+
+	assert.NoError(t, RunServiceCommand("nginx.service", "reload"))
+	assert.NoError(t, RunServiceCommand("nginx.service", "stop"))
+	assert.ErrorIs(t, RunServiceCommand("nginx.service", "checkconfig"), ErrorUnknownServiceCommand)
+	assert.Equal(t, []string{"reload nginx.service", "stop nginx.service"}, conn.calls)
+}
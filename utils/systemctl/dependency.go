@@ -0,0 +1,62 @@
+package systemctl
+
+import "context"
+
+// ServiceDependencies lists how a unit relates to other units.
+type ServiceDependencies struct {
+	// Requires and Wants are the units started along with this one, strictly or not.
+	Requires []string
+	Wants    []string
+
+	// After are the units this one is ordered to start after.
+	After []string
+
+	// RequiredBy and WantedBy are the units that depend on this one, and so are affected by stopping it.
+	RequiredBy []string
+	WantedBy   []string
+}
+
+// ListServiceDependencies returns the `Requires=`, `Wants=` and `After=` relationships of the unit, along with
+// the units depending on it, e.g. to warn before stopping a service other apps rely on.
+func ListServiceDependencies(name string) (ServiceDependencies, error) {
+	return ListServiceDependenciesContext(context.Background(), name)
+}
+
+// ListServiceDependenciesContext is like ListServiceDependencies, but with a caller-supplied context.
+func ListServiceDependenciesContext(ctx context.Context, name string) (ServiceDependencies, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return ServiceDependencies{}, err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return ServiceDependencies{}, err
+	}
+
+	return serviceDependencies(properties), nil
+}
+
+func serviceDependencies(properties map[string]interface{}) ServiceDependencies {
+	units := func(property string) []string {
+		if names, ok := properties[property].([]string); ok {
+			return names
+		}
+
+		return []string{}
+	}
+
+	return ServiceDependencies{
+		Requires:   units("Requires"),
+		Wants:      units("Wants"),
+		After:      units("After"),
+		RequiredBy: units("RequiredBy"),
+		WantedBy:   units("WantedBy"),
+	}
+}
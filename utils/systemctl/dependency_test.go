@@ -0,0 +1,31 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListServiceDependencies(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos-gateway.service": {
+			"Requires":   []string{"sysinit.target"},
+			"Wants":      []string{"network-online.target"},
+			"After":      []string{"network-online.target", "sysinit.target"},
+			"RequiredBy": []string{"casaos.service"},
+		},
+	}).use(t)
+
+	dependencies, err := ListServiceDependencies("casaos-gateway.service")
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceDependencies{
+		Requires:   []string{"sysinit.target"},
+		Wants:      []string{"network-online.target"},
+		After:      []string{"network-online.target", "sysinit.target"},
+		RequiredBy: []string{"casaos.service"},
+		WantedBy:   []string{},
+	}, dependencies)
+
+	_, err = ListServiceDependencies("missing.service")
+	assert.ErrorIs(t, err, errNoSuchUnit)
+}
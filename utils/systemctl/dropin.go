@@ -0,0 +1,73 @@
+package systemctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrorInvalidUnitName = errors.New("unit name must be non-empty and must not contain `/`")
+
+var (
+	// unitConfigDir is where local unit configuration, including drop-ins, lives.
+	unitConfigDir = "/etc/systemd/system"
+
+	// runtimeUnitConfigDir is like unitConfigDir, but for configuration that does not survive a reboot.
+	runtimeUnitConfigDir = "/run/systemd/system"
+)
+
+// dropInPrefix marks the drop-ins managed by this package.
+const dropInPrefix = "50-casaos-"
+
+// dropInPath returns where the drop-in identified by key for the unit is kept under dir. The name is checked, so
+// it cannot point outside dir.
+func dropInPath(dir, name, key string) (string, error) {
+	if name == "" || strings.ContainsRune(name, '/') {
+		return "", ErrorInvalidUnitName
+	}
+
+	return filepath.Join(dir, name+".d", dropInPrefix+key+".conf"), nil
+}
+
+// renderDropIn renders a drop-in setting each key of section in order.
+func renderDropIn(section string, entries [][2]string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[%s]\n", section)
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%s=%s\n", entry[0], entry[1])
+	}
+
+	return b.String()
+}
+
+// writeDropIn writes the drop-in identified by key for the unit under dir. The caller is responsible for reloading systemd.
+func writeDropIn(dir, name, key, content string) error {
+	path, err := dropInPath(dir, name, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(content), 0o644) //nolint:gosec // G306: unit configuration is world readable
+}
+
+// removeDropIn removes the drop-in identified by key for the unit under dir, if there is one. The caller is
+// responsible for reloading systemd.
+func removeDropIn(dir, name, key string) error {
+	path, err := dropInPath(dir, name, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package systemctl
+
+import "context"
+
+// Enablement is the `UnitFileState` of a unit, i.e. whether and how it is enabled.
+type Enablement string
+
+const (
+	EnablementEnabled        Enablement = "enabled"
+	EnablementEnabledRuntime Enablement = "enabled-runtime"
+	EnablementLinked         Enablement = "linked"
+	EnablementLinkedRuntime  Enablement = "linked-runtime"
+	EnablementAlias          Enablement = "alias"
+	EnablementMasked         Enablement = "masked"
+	EnablementMaskedRuntime  Enablement = "masked-runtime"
+
+	// EnablementStatic means the unit has no `[Install]` section, so it only starts as a dependency of another.
+	EnablementStatic Enablement = "static"
+
+	// EnablementIndirect means the unit is not enabled itself, but one of its `Also=` units is.
+	EnablementIndirect Enablement = "indirect"
+
+	EnablementDisabled  Enablement = "disabled"
+	EnablementGenerated Enablement = "generated"
+	EnablementTransient Enablement = "transient"
+
+	// EnablementBad means the unit file is invalid, or does not exist.
+	EnablementBad Enablement = "bad"
+)
+
+// Enabled reports whether the unit starts at boot, persistently or until the next reboot.
+func (e Enablement) Enabled() bool {
+	return e == EnablementEnabled || e == EnablementEnabledRuntime
+}
+
+// Masked reports whether the unit is masked, persistently or until the next reboot.
+func (e Enablement) Masked() bool {
+	return e == EnablementMasked || e == EnablementMaskedRuntime
+}
+
+// GetServiceEnablement returns whether and how the unit is enabled. Unlike IsServiceEnabled, this tells apart
+// e.g. a masked unit from a disabled one.
+func GetServiceEnablement(name string) (Enablement, error) {
+	return GetServiceEnablementContext(context.Background(), name)
+}
+
+// GetServiceEnablementContext is like GetServiceEnablement, but with a caller-supplied context.
+func GetServiceEnablementContext(ctx context.Context, name string) (Enablement, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	state, err := getStringProperty(ctx, conn, name, "UnitFileState")
+	if err != nil {
+		return "", err
+	}
+
+	// systemd reports units without a unit file with an empty state
+	if state == "" {
+		return EnablementBad, nil
+	}
+
+	return Enablement(state), nil
+}
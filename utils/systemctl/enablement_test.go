@@ -0,0 +1,34 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetServiceEnablement(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service":         {"UnitFileState": "enabled"},
+		"casaos-gateway.service": {"UnitFileState": "enabled-runtime"},
+		"smbd.service":           {"UnitFileState": "masked"},
+		"nmbd.service":           {"UnitFileState": "disabled"},
+		"missing.service":        {"UnitFileState": ""},
+	}).use(t)
+
+	for name, expected := range map[string]Enablement{
+		"casaos.service":         EnablementEnabled,
+		"casaos-gateway.service": EnablementEnabledRuntime,
+		"smbd.service":           EnablementMasked,
+		"nmbd.service":           EnablementDisabled,
+		"missing.service":        EnablementBad,
+	} {
+		enablement, err := GetServiceEnablement(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, enablement, name)
+	}
+
+	assert.True(t, EnablementEnabledRuntime.Enabled())
+	assert.False(t, EnablementStatic.Enabled())
+	assert.True(t, EnablementMaskedRuntime.Masked())
+	assert.False(t, EnablementDisabled.Masked())
+}
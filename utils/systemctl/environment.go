@@ -0,0 +1,106 @@
+package systemctl
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// GetServiceEnvironment returns the environment variables set for the service by `Environment=`, in its unit
+// file and drop-ins alike. Variables from `EnvironmentFile=` are not included.
+func GetServiceEnvironment(name string) (map[string]string, error) {
+	return GetServiceEnvironmentContext(context.Background(), name)
+}
+
+// GetServiceEnvironmentContext is like GetServiceEnvironment, but with a caller-supplied context.
+func GetServiceEnvironmentContext(ctx context.Context, name string) (map[string]string, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitTypePropertiesContext(ctx, name, "Service")
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, _ := properties["Environment"].([]string)
+
+	env := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		if key, value, ok := strings.Cut(assignment, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return env, nil
+}
+
+// SetServiceEnvironment sets the environment variables of the service in a drop-in under
+// /etc/systemd/system/<name>.d/ and reloads systemd, replacing those set by a previous call. Variables set by the
+// unit file itself are overridden, not removed. An empty env removes the drop-in. The change takes effect the
+// next time the service starts.
+func SetServiceEnvironment(name string, env map[string]string) error {
+	return SetServiceEnvironmentContext(context.Background(), name, env)
+}
+
+// SetServiceEnvironmentContext is like SetServiceEnvironment, but with a caller-supplied context.
+func SetServiceEnvironmentContext(ctx context.Context, name string, env map[string]string) error {
+	if err := localOnly(ctx); err != nil {
+		return err
+	}
+
+	for key, value := range env {
+		if key == "" || strings.ContainsAny(key, "= \t\r\n") || strings.ContainsAny(value, "\r\n") {
+			return ErrorInvalidOverride
+		}
+	}
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	dir, err := configDir(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	if len(env) == 0 {
+		if err := removeDropIn(dir, name, "environment"); err != nil {
+			return err
+		}
+
+		return conn.ReloadContext(ctx)
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	entries := make([][2]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, [2]string{"Environment", quoteEnvironment(key + "=" + env[key])})
+	}
+
+	if err := writeDropIn(dir, name, "environment", renderDropIn("Service", entries)); err != nil {
+		return err
+	}
+
+	return conn.ReloadContext(ctx)
+}
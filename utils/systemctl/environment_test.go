@@ -0,0 +1,35 @@
+package systemctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceEnvironment(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"Environment": []string{"TZ=UTC", "GREETING=hello=world"}},
+	})
+	conn.use(t)
+
+	env, err := GetServiceEnvironment("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"TZ": "UTC", "GREETING": "hello=world"}, env)
+
+	assert.ErrorIs(t, SetServiceEnvironment("casaos.service", map[string]string{"BAD KEY": "x"}), ErrorInvalidOverride)
+
+	path := filepath.Join(unitConfigDir, "casaos.service.d", "50-casaos-environment.conf")
+
+	assert.NoError(t, SetServiceEnvironment("casaos.service", map[string]string{"TZ": "Europe/Berlin", "DATA_ROOT": "/DATA"}))
+	assertFileContent(t, path, "[Service]\nEnvironment=\"DATA_ROOT=/DATA\"\nEnvironment=\"TZ=Europe/Berlin\"\n")
+
+	assert.NoError(t, SetServiceEnvironment("casaos.service", nil))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Equal(t, []string{"daemon-reload", "daemon-reload"}, conn.calls)
+}
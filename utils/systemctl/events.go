@@ -0,0 +1,113 @@
+package systemctl
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// ServiceEvent reports that the state of a unit changed, e.g. it became `active`, `failed` or `inactive`.
+type ServiceEvent struct {
+	Name        string
+	ActiveState string
+	SubState    string
+}
+
+// subscription is the subset of *dbus.Conn used to receive unit property changes.
+type subscription interface {
+	Close()
+	Subscribe() error
+	SetPropertiesSubscriber(updateCh chan<- *dbus.PropertiesUpdate, errCh chan<- error)
+}
+
+// newSubscription opens a dedicated connection for a subscription, as systemd sends each connection's signals
+// to a single subscriber.
+var newSubscription = func(ctx context.Context) (subscription, error) {
+	if host := remoteHost(ctx); host != "" {
+		return NewRemoteConnection(context.Background(), host)
+	}
+
+	if isUserSession(ctx) {
+		return dbus.NewUserConnectionContext(context.Background())
+	}
+
+	return dialSystem()
+}
+
+// SubscribeServiceEvents delivers an event whenever the active state of a unit matching the glob pattern
+// changes, as signaled by systemd, until ctx is canceled, after which the channel is closed. An empty
+// pattern matches all units.
+func SubscribeServiceEvents(ctx context.Context, pattern string) (<-chan ServiceEvent, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	conn, err := newSubscription(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Subscribe(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	updates := make(chan *dbus.PropertiesUpdate, 256)
+	errs := make(chan error, 1)
+
+	conn.SetPropertiesSubscriber(updates, errs)
+
+	events := make(chan ServiceEvent)
+
+	go func() {
+		defer close(events)
+		defer conn.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			// only reports that updates were dropped because the channel was full
+			case <-errs:
+
+			case update := <-updates:
+				event, ok := serviceEvent(update, pattern)
+				if !ok {
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func serviceEvent(update *dbus.PropertiesUpdate, pattern string) (ServiceEvent, bool) {
+	if pattern != "" {
+		if matched, _ := filepath.Match(pattern, update.UnitName); !matched {
+			return ServiceEvent{}, false
+		}
+	}
+
+	activeState, ok := update.Changed["ActiveState"]
+	if !ok {
+		return ServiceEvent{}, false
+	}
+
+	event := ServiceEvent{Name: update.UnitName}
+	event.ActiveState, _ = activeState.Value().(string)
+
+	if subState, ok := update.Changed["SubState"]; ok {
+		event.SubState, _ = subState.Value().(string)
+	}
+
+	return event, true
+}
@@ -0,0 +1,76 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSubscription struct {
+	updates chan<- *dbus.PropertiesUpdate
+	closed  chan struct{}
+}
+
+func (s *fakeSubscription) Close() {
+	close(s.closed)
+}
+
+func (s *fakeSubscription) Subscribe() error {
+	return nil
+}
+
+func (s *fakeSubscription) SetPropertiesSubscriber(updateCh chan<- *dbus.PropertiesUpdate, errCh chan<- error) {
+	s.updates = updateCh
+}
+
+func propertiesUpdate(unit string, changed map[string]interface{}) *dbus.PropertiesUpdate {
+	update := &dbus.PropertiesUpdate{UnitName: unit, Changed: map[string]godbus.Variant{}}
+	for name, value := range changed {
+		update.Changed[name] = godbus.MakeVariant(value)
+	}
+
+	return update
+}
+
+func TestSubscribeServiceEvents(t *testing.T) {
+	conn := &fakeSubscription{closed: make(chan struct{})}
+
+	original := newSubscription
+	newSubscription = func(ctx context.Context) (subscription, error) {
+		return conn, nil
+	}
+
+	t.Cleanup(func() { newSubscription = original })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := SubscribeServiceEvents(ctx, "casaos*.service")
+	assert.NoError(t, err)
+
+	conn.updates <- propertiesUpdate("casaos.service", map[string]interface{}{"ActiveState": "activating", "SubState": "start"})
+	conn.updates <- propertiesUpdate("smbd.service", map[string]interface{}{"ActiveState": "active", "SubState": "running"})
+	conn.updates <- propertiesUpdate("casaos.service", map[string]interface{}{"MemoryCurrent": uint64(1024)})
+	conn.updates <- propertiesUpdate("casaos-gateway.service", map[string]interface{}{"ActiveState": "failed", "SubState": "failed"})
+
+	assert.Equal(t, ServiceEvent{Name: "casaos.service", ActiveState: "activating", SubState: "start"}, <-events)
+	assert.Equal(t, ServiceEvent{Name: "casaos-gateway.service", ActiveState: "failed", SubState: "failed"}, <-events)
+
+	cancel()
+
+	select {
+	case <-conn.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed after cancel")
+	}
+
+	_, ok := <-events
+	assert.False(t, ok)
+
+	_, err = SubscribeServiceEvents(ctx, "[")
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package systemctl
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecCommand is one command line of a service, e.g. one `ExecStart=` line.
+type ExecCommand struct {
+	Path string
+	Argv []string
+
+	// IgnoreErrors is true when the command was prefixed with `-`, i.e. its failure is not fatal.
+	IgnoreErrors bool
+}
+
+// GetExecStart returns the commands systemd runs to start the service, in order. A service may have several
+// `ExecStart=` lines, e.g. with `Type=oneshot`.
+func GetExecStart(name string) ([]ExecCommand, error) {
+	return GetExecStartContext(context.Background(), name)
+}
+
+// GetExecStartContext is like GetExecStart, but with a caller-supplied context.
+func GetExecStartContext(ctx context.Context, name string) ([]ExecCommand, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitTypePropertiesContext(ctx, name, "Service")
+	if err != nil {
+		return nil, err
+	}
+
+	return execCommands(properties["ExecStart"])
+}
+
+// execCommands decodes an exec command property, which has the D-Bus signature `a(sasbttttuii)`.
+func execCommands(value interface{}) ([]ExecCommand, error) {
+	if value == nil {
+		return []ExecCommand{}, nil
+	}
+
+	tuples, ok := value.([][]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T of exec command property", value)
+	}
+
+	commands := make([]ExecCommand, 0, len(tuples))
+
+	for _, tuple := range tuples {
+		if len(tuple) < 3 {
+			return nil, fmt.Errorf("unexpected exec command %v", tuple)
+		}
+
+		path, ok := tuple[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T of exec command path", tuple[0])
+		}
+
+		argv, ok := tuple[1].([]string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T of exec command arguments", tuple[1])
+		}
+
+		ignoreErrors, ok := tuple[2].(bool)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T of exec command flags", tuple[2])
+		}
+
+		commands = append(commands, ExecCommand{
+			Path:         path,
+			Argv:         argv,
+			IgnoreErrors: ignoreErrors,
+		})
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,34 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecCommands(t *testing.T) {
+	commands, err := execCommands([][]interface{}{
+		{"/usr/bin/casaos", []string{"/usr/bin/casaos", "-c", "/etc/casaos/casaos.conf"}, false, uint64(0), uint64(0), uint64(0), uint64(0), uint32(0), int32(0), int32(0)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []ExecCommand{
+		{Path: "/usr/bin/casaos", Argv: []string{"/usr/bin/casaos", "-c", "/etc/casaos/casaos.conf"}},
+	}, commands)
+
+	commands, err = execCommands([][]interface{}{
+		{"/usr/bin/mkdir", []string{"mkdir", "-p", "/var/lib/casaos"}, true, uint64(0), uint64(0), uint64(0), uint64(0), uint32(0), int32(0), int32(0)},
+		{"/usr/bin/casaos-migration-tool", []string{"casaos-migration-tool"}, false, uint64(0), uint64(0), uint64(0), uint64(0), uint32(0), int32(0), int32(0)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []ExecCommand{
+		{Path: "/usr/bin/mkdir", Argv: []string{"mkdir", "-p", "/var/lib/casaos"}, IgnoreErrors: true},
+		{Path: "/usr/bin/casaos-migration-tool", Argv: []string{"casaos-migration-tool"}},
+	}, commands)
+
+	commands, err = execCommands(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, commands)
+
+	_, err = execCommands("/usr/bin/casaos")
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package systemctl
+
+import (
+	"context"
+	"encoding/hex"
+)
+
+// FailedService describes a unit in failed state.
+type FailedService struct {
+	Name string
+
+	// Result is why the unit failed, e.g. `exit-code`, `signal`, `timeout` or `start-limit-hit`.
+	Result string
+
+	// ExecMainStatus is the exit code, or signal number, of the main process of a service, zero for other units.
+	ExecMainStatus int
+
+	// InvocationID identifies the failed run of the unit, e.g. to find its logs with `journalctl _SYSTEMD_INVOCATION_ID=`.
+	InvocationID string
+}
+
+// ListFailedServices returns all units in failed state, e.g. for a system health panel.
+func ListFailedServices() ([]FailedService, error) {
+	return ListFailedServicesContext(context.Background())
+}
+
+// ListFailedServicesContext is like ListFailedServices, but with a caller-supplied context.
+func ListFailedServicesContext(ctx context.Context) ([]FailedService, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	units, err := conn.ListUnitsByPatternsContext(ctx, []string{"failed"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make([]FailedService, 0, len(units))
+
+	for _, unit := range units {
+		properties, err := conn.GetUnitPropertiesContext(ctx, unit.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		service := FailedService{Name: unit.Name}
+
+		if id, ok := properties["InvocationID"].([]byte); ok && len(id) > 0 {
+			service.InvocationID = hex.EncodeToString(id)
+		}
+
+		if typ := unitType(unit.Name); typ != "" {
+			typeProperties, err := conn.GetUnitTypePropertiesContext(ctx, unit.Name, typ)
+			if err != nil {
+				return nil, err
+			}
+
+			service.Result, _ = typeProperties["Result"].(string)
+
+			status, _ := typeProperties["ExecMainStatus"].(int32)
+			service.ExecMainStatus = int(status)
+		}
+
+		failed = append(failed, service)
+	}
+
+	return failed, nil
+}
@@ -0,0 +1,27 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFailedServices(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "active"},
+		"smbd.service": {
+			"ActiveState":    "failed",
+			"Result":         "exit-code",
+			"ExecMainStatus": int32(1),
+			"InvocationID":   []byte{0xde, 0xad, 0xbe, 0xef},
+		},
+		"casaos-backup.timer": {"ActiveState": "failed", "Result": "resources"},
+	}).use(t)
+
+	failed, err := ListFailedServices()
+	assert.NoError(t, err)
+	assert.Equal(t, []FailedService{
+		{Name: "casaos-backup.timer", Result: "resources"},
+		{Name: "smbd.service", Result: "exit-code", ExecMainStatus: 1, InvocationID: "deadbeef"},
+	}, failed)
+}
@@ -0,0 +1,30 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreezeService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos-indexer.service": {"ActiveState": "active", "FreezerState": "running"},
+		"smbd.service":           {"ActiveState": "inactive", "FreezerState": "running"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, FreezeService("casaos-indexer.service"))
+
+	frozen, err := IsServiceFrozen("casaos-indexer.service")
+	assert.NoError(t, err)
+	assert.True(t, frozen)
+
+	assert.NoError(t, ThawService("casaos-indexer.service"))
+
+	frozen, err = IsServiceFrozen("casaos-indexer.service")
+	assert.NoError(t, err)
+	assert.False(t, frozen)
+
+	assert.ErrorIs(t, FreezeService("smbd.service"), errNotApplicable)
+	assert.Equal(t, []string{"freeze casaos-indexer.service", "thaw casaos-indexer.service", "freeze smbd.service"}, conn.calls)
+}
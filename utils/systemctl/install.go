@@ -0,0 +1,231 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrorInvalidServiceDefinition = errors.New("service definition requires a valid name and ExecStart")
+
+// ServiceDefinition describes a service installed by InstallService.
+type ServiceDefinition struct {
+	// Name of the unit, e.g. `casaos-app.service`. The `.service` suffix is added if missing.
+	Name        string
+	Description string
+
+	// ExecStart is the command line started by the service, as it would be written in the unit file.
+	ExecStart   string
+	User        string
+	Environment map[string]string
+
+	// Restart is the `Restart=` policy, e.g. `on-failure` or `always`. systemd's default (`no`) applies if empty.
+	Restart string
+
+	// WantedBy lists the targets the service is enabled for. Defaults to `multi-user.target`.
+	WantedBy []string
+}
+
+// InstallService writes a unit file for the service under /etc/systemd/system, reloads systemd and enables it.
+// An existing unit file of the same name is replaced.
+func InstallService(def ServiceDefinition) error {
+	return InstallServiceContext(context.Background(), def)
+}
+
+// InstallServiceContext is like InstallService, but with a caller-supplied context.
+func InstallServiceContext(ctx context.Context, def ServiceDefinition) (err error) {
+	if err := localOnly(ctx); err != nil {
+		return err
+	}
+
+	if !def.valid() {
+		return ErrorInvalidServiceDefinition
+	}
+
+	def.Name = serviceUnitName(def.Name)
+
+	defer func() { audit(OperationInstall, def.Name, err) }()
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	dir, err := configDir(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, def.Name), []byte(renderUnitFile(def)), 0o644); err != nil { //nolint:gosec // G306: unit configuration is world readable
+		return err
+	}
+
+	if err := conn.ReloadContext(ctx); err != nil {
+		return err
+	}
+
+	_, _, err = conn.EnableUnitFilesContext(ctx, []string{def.Name}, false, true)
+	return err
+}
+
+// valid reports whether def can be rendered into a unit file as is. Line breaks are rejected everywhere, as they
+// would start new directives or sections in the unit file.
+func (def ServiceDefinition) valid() bool {
+	if def.Name == "" || strings.ContainsRune(def.Name, '/') || def.ExecStart == "" {
+		return false
+	}
+
+	fields := append([]string{def.Name, def.Description, def.ExecStart, def.User, def.Restart}, def.WantedBy...)
+	for _, field := range fields {
+		if strings.ContainsAny(field, "\r\n") {
+			return false
+		}
+	}
+
+	for _, target := range def.WantedBy {
+		if target == "" || strings.ContainsAny(target, " \t") {
+			return false
+		}
+	}
+
+	for key, value := range def.Environment {
+		if key == "" || strings.ContainsAny(key, "= \t\r\n") || strings.ContainsAny(value, "\r\n") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func renderUnitFile(def ServiceDefinition) string {
+	unit := [][2]string{}
+	if def.Description != "" {
+		unit = append(unit, [2]string{"Description", def.Description})
+	}
+
+	service := [][2]string{{"ExecStart", def.ExecStart}}
+	if def.User != "" {
+		service = append(service, [2]string{"User", def.User})
+	}
+
+	keys := make([]string, 0, len(def.Environment))
+	for key := range def.Environment {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		service = append(service, [2]string{"Environment", quoteEnvironment(key + "=" + def.Environment[key])})
+	}
+
+	if def.Restart != "" {
+		service = append(service, [2]string{"Restart", def.Restart})
+	}
+
+	wantedBy := def.WantedBy
+	if len(wantedBy) == 0 {
+		wantedBy = []string{"multi-user.target"}
+	}
+
+	install := [][2]string{{"WantedBy", strings.Join(wantedBy, " ")}}
+
+	return renderDropIn("Unit", unit) + "\n" + renderDropIn("Service", service) + "\n" + renderDropIn("Install", install)
+}
+
+// quoteEnvironment quotes an `Environment=` assignment so that whitespace, quotes, backslashes and specifiers
+// in the value are taken literally.
+func quoteEnvironment(assignment string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%")
+	return `"` + replacer.Replace(assignment) + `"`
+}
+
+// UninstallService stops and disables the service, then removes its unit file and drop-ins from
+// /etc/systemd/system and /run/systemd/system and reloads systemd. Vendor unit files, e.g. under
+// /usr/lib/systemd/system, are left alone. The `.service` suffix is added to name if missing, as by InstallService.
+func UninstallService(name string) error {
+	return UninstallServiceContext(context.Background(), name)
+}
+
+// UninstallServiceContext is like UninstallService, but with a caller-supplied context.
+func UninstallServiceContext(ctx context.Context, name string) error {
+	return uninstallUnit(ctx, serviceUnitName(name))
+}
+
+// uninstallUnit does the work of UninstallService for a unit of any type, e.g. a timer installed by CreateTimer.
+func uninstallUnit(ctx context.Context, name string) (err error) {
+	if err := localOnly(ctx); err != nil {
+		return err
+	}
+
+	if strings.ContainsRune(name, '/') || strings.HasPrefix(name, ".") {
+		return ErrorInvalidServiceDefinition
+	}
+
+	defer func() { audit(OperationUninstall, name, err) }()
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	state, err := getStringProperty(ctx, conn, name, "ActiveState")
+	if err != nil {
+		return err
+	}
+
+	if state != "inactive" && state != "failed" {
+		if err := stopUnit(ctx, conn, name); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.DisableUnitFilesContext(ctx, []string{name}, false); err != nil {
+		return err
+	}
+
+	for _, runtime := range []bool{false, true} {
+		dir, err := configDir(ctx, runtime)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if err := os.RemoveAll(filepath.Join(dir, name+".d")); err != nil {
+			return err
+		}
+	}
+
+	return conn.ReloadContext(ctx)
+}
+
+// serviceUnitName adds the `.service` suffix to name if missing.
+func serviceUnitName(name string) string {
+	if !strings.HasSuffix(name, ".service") {
+		return name + ".service"
+	}
+
+	return name
+}
@@ -0,0 +1,111 @@
+package systemctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallService(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos-app.service": {"UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	assert.ErrorIs(t, InstallService(ServiceDefinition{Name: "casaos-app"}), ErrorInvalidServiceDefinition)
+	assert.ErrorIs(t, InstallService(ServiceDefinition{Name: "../casaos-app", ExecStart: "/usr/bin/app"}), ErrorInvalidServiceDefinition)
+
+	// line breaks would inject directives into the unit file
+	for _, def := range []ServiceDefinition{
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", Description: "x\n[Service]\nUser=root"},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app\nUser=root"},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", User: "casaos\r\nUser=root"},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", Restart: "always\nUser=root"},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", WantedBy: []string{"multi-user.target\n[Service]"}},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", WantedBy: []string{"multi-user.target graphical.target"}},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", Environment: map[string]string{"DEBUG": "1\nUser=root"}},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", Environment: map[string]string{"A=B": "1"}},
+		{Name: "casaos-app", ExecStart: "/usr/bin/app", Environment: map[string]string{"A B": "1"}},
+	} {
+		assert.ErrorIs(t, InstallService(def), ErrorInvalidServiceDefinition)
+	}
+
+	assert.NoError(t, InstallService(ServiceDefinition{
+		Name:        "casaos-app",
+		Description: "CasaOS App",
+		ExecStart:   "/usr/bin/app --port 8080",
+		User:        "casaos",
+		Environment: map[string]string{"TZ": "UTC", "GREETING": `say "hi" 100%`},
+		Restart:     "on-failure",
+	}))
+
+	assert.Equal(t, []string{"daemon-reload", "enable casaos-app.service"}, conn.calls)
+	assertFileContent(t, filepath.Join(unitConfigDir, "casaos-app.service"), `[Unit]
+Description=CasaOS App
+
+[Service]
+ExecStart=/usr/bin/app --port 8080
+User=casaos
+Environment="GREETING=say \"hi\" 100%%"
+Environment="TZ=UTC"
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`)
+}
+
+func TestUninstallService(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos-app.service": {"ActiveState": "active", "UnitFileState": "enabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(unitConfigDir, "casaos-app.service"), []byte("[Service]\n"), 0o644))
+	assert.NoError(t, writeDropIn(unitConfigDir, "casaos-app.service", "log-rate-limit", "[Service]\n"))
+	assert.NoError(t, writeDropIn(runtimeUnitConfigDir, "casaos-app.service", "oom-score-adjust", "[Service]\n"))
+
+	assert.NoError(t, UninstallService("casaos-app.service"))
+	assert.Equal(t, []string{"stop casaos-app.service", "disable casaos-app.service", "daemon-reload"}, conn.calls)
+
+	for _, path := range []string{
+		filepath.Join(unitConfigDir, "casaos-app.service"),
+		filepath.Join(unitConfigDir, "casaos-app.service.d"),
+		filepath.Join(runtimeUnitConfigDir, "casaos-app.service.d"),
+	} {
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err), path)
+	}
+}
+
+func TestInstallAndUninstallService(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos-app.service": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, InstallService(ServiceDefinition{Name: "casaos-app", ExecStart: "/usr/bin/app"}))
+	_, err := os.Stat(filepath.Join(unitConfigDir, "casaos-app.service"))
+	assert.NoError(t, err)
+
+	conn.calls = nil
+
+	assert.ErrorIs(t, UninstallService(""), ErrorInvalidServiceDefinition)
+	assert.ErrorIs(t, UninstallService("../casaos-app"), ErrorInvalidServiceDefinition)
+	assert.Empty(t, conn.calls)
+
+	// the same name is accepted without the suffix
+	assert.NoError(t, UninstallService("casaos-app"))
+	assert.Equal(t, []string{"disable casaos-app.service", "daemon-reload"}, conn.calls)
+
+	_, err = os.Stat(filepath.Join(unitConfigDir, "casaos-app.service"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,236 @@
+package systemctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// GetJournalUsage returns approximately how many bytes of journal the unit has produced, by summing the sizes
+// of its journal entries. Zero is returned for units without journal data.
+func GetJournalUsage(name string) (int64, error) {
+	return GetJournalUsageContext(context.Background(), name)
+}
+
+// GetJournalUsageContext is like GetJournalUsage, but with a caller-supplied context. Scanning a large journal
+// can take well over the default timeout, so only ctx bounds it.
+func GetJournalUsageContext(ctx context.Context, name string) (int64, error) {
+	if err := localOnly(ctx); err != nil {
+		return 0, err
+	}
+
+	// the journal is streamed rather than read into memory, as a chatty service can have gigabytes of it
+	cmd := journalctl(ctx, name)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	size, err := journalEntriesSize(stdout)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+
+		return 0, err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// journalctl returns the command printing the journal entries of the unit as JSON, one entry per line.
+func journalctl(ctx context.Context, name string, args ...string) *exec.Cmd {
+	unit := "--unit=" + name
+	if isUserSession(ctx) {
+		unit = "--user-unit=" + name
+	}
+
+	args = append([]string{"--quiet", "--no-pager", "--output=json", unit}, args...)
+
+	return exec.CommandContext(ctx, "journalctl", args...) //nolint:gosec // G204: arguments are not passed through a shell
+}
+
+// LogEntry is a message logged by a service.
+type LogEntry struct {
+	Time     time.Time
+	Priority int
+	Message  string
+}
+
+// GetServiceLogs returns up to the given number of the latest journal entries of the unit, oldest first.
+func GetServiceLogs(name string, lines int) ([]LogEntry, error) {
+	return GetServiceLogsContext(context.Background(), name, lines)
+}
+
+// GetServiceLogsContext is like GetServiceLogs, but with a caller-supplied context.
+func GetServiceLogsContext(ctx context.Context, name string, lines int) ([]LogEntry, error) {
+	if err := localOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	output, err := journalctl(ctx, name, "--lines="+strconv.Itoa(lines)).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LogEntry, 0, lines)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJournalEntrySize)
+
+	for scanner.Scan() {
+		entry, err := parseLogEntry(scanner.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// maxJournalEntrySize bounds the size of a single journal entry in JSON.
+const maxJournalEntrySize = 4 * 1024 * 1024
+
+// journalEntry holds the fields of a journal entry in JSON that make up a LogEntry. Fields may be a string,
+// an array of bytes if not valid UTF-8, or null if too large.
+type journalEntry struct {
+	RealtimeTimestamp string          `json:"__REALTIME_TIMESTAMP"`
+	Priority          string          `json:"PRIORITY"`
+	Message           json.RawMessage `json:"MESSAGE"`
+}
+
+func parseLogEntry(line []byte) (LogEntry, error) {
+	var raw journalEntry
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return LogEntry{}, err
+	}
+
+	entry := LogEntry{
+		Priority: -1,
+	}
+
+	if usec, err := strconv.ParseUint(raw.RealtimeTimestamp, 10, 64); err == nil {
+		entry.Time = usecToTime(usec)
+	}
+
+	if priority, err := strconv.Atoi(raw.Priority); err == nil {
+		entry.Priority = priority
+	}
+
+	var message string
+	if err := json.Unmarshal(raw.Message, &message); err == nil {
+		entry.Message = message
+		return entry, nil
+	}
+
+	var data []byte
+	var values []int
+	if err := json.Unmarshal(raw.Message, &values); err == nil {
+		for _, value := range values {
+			data = append(data, byte(value))
+		}
+
+		entry.Message = string(data)
+	}
+
+	return entry, nil
+}
+
+// journalEntriesSize sums the sizes of the journal entries read from r, one JSON object per line.
+func journalEntriesSize(r io.Reader) (int64, error) {
+	var size int64
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] == '{' {
+			size += int64(len(line))
+		}
+
+		if err == io.EOF {
+			return size, nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// StreamServiceLogs follows the journal of the unit, delivering each new entry until ctx is canceled,
+// after which the channel is closed.
+func StreamServiceLogs(ctx context.Context, name string) (<-chan LogEntry, error) {
+	if err := localOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	return streamLogs(ctx, journalctl(ctx, name, "--follow", "--lines=0"))
+}
+
+// streamLogs runs cmd, which must be bound to ctx, and delivers the entries it prints.
+func streamLogs(ctx context.Context, cmd *exec.Cmd) (<-chan LogEntry, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan LogEntry)
+	done := make(chan struct{})
+
+	// cmd is killed once ctx is done, but its output may still be held open by its children
+	go func() {
+		select {
+		case <-ctx.Done():
+			stdout.Close()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(ch)
+		defer close(done)
+		defer cmd.Wait() //nolint:errcheck
+
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxJournalEntrySize)
+
+		for scanner.Scan() {
+			entry, err := parseLogEntry(scanner.Bytes())
+			if err != nil {
+				continue
+			}
+
+			select {
+			case ch <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,100 @@
+package systemctl
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalEntriesSize(t *testing.T) {
+	entries := []string{
+		`{"_SYSTEMD_UNIT":"casaos.service","MESSAGE":"started","PRIORITY":"6"}`,
+		`{"_SYSTEMD_UNIT":"casaos.service","MESSAGE":"listening on :80","PRIORITY":"6"}`,
+		`{"_SYSTEMD_UNIT":"casaos.service","MESSAGE":[98,105,110],"PRIORITY":"3"}`,
+	}
+
+	expected := int64(len(entries[0]) + len(entries[1]) + len(entries[2]))
+
+	size, err := journalEntriesSize(strings.NewReader(strings.Join(entries, "\n") + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, size)
+
+	// last entry without trailing newline
+	size, err = journalEntriesSize(strings.NewReader(strings.Join(entries, "\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, size)
+
+	size, err = journalEntriesSize(strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestParseLogEntry(t *testing.T) {
+	entry, err := parseLogEntry([]byte(`{"__REALTIME_TIMESTAMP":"1682942400000000","PRIORITY":"3","MESSAGE":"failed to bind :80","_SYSTEMD_UNIT":"casaos.service"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, LogEntry{Time: time.UnixMicro(1682942400000000), Priority: 3, Message: "failed to bind :80"}, entry)
+
+	// non UTF-8 messages are an array of bytes
+	entry, err = parseLogEntry([]byte(`{"__REALTIME_TIMESTAMP":"1682942400000001","PRIORITY":"6","MESSAGE":[98,105,110]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "bin", entry.Message)
+
+	// messages too large for the journal are null
+	entry, err = parseLogEntry([]byte(`{"__REALTIME_TIMESTAMP":"1682942400000002","MESSAGE":null}`))
+	assert.NoError(t, err)
+	assert.Equal(t, LogEntry{Time: time.UnixMicro(1682942400000002), Priority: -1}, entry)
+
+	_, err = parseLogEntry([]byte(`-- No entries --`))
+	assert.Error(t, err)
+}
+
+func TestJournalctl(t *testing.T) {
+	cmd := journalctl(context.Background(), "casaos.service", "--lines=10")
+	assert.Equal(t, []string{"journalctl", "--quiet", "--no-pager", "--output=json", "--unit=casaos.service", "--lines=10"}, cmd.Args)
+
+	cmd = journalctl(WithUserSession(context.Background()), "casaos-helper.service")
+	assert.Equal(t, []string{"journalctl", "--quiet", "--no-pager", "--output=json", "--user-unit=casaos-helper.service"}, cmd.Args)
+}
+
+func TestStreamLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	script := `echo '{"__REALTIME_TIMESTAMP":"1682942400000000","PRIORITY":"6","MESSAGE":"started"}'
+echo 'not json'
+echo '{"__REALTIME_TIMESTAMP":"1682942401000000","PRIORITY":"4","MESSAGE":"slow start"}'
+sleep 10`
+
+	ch, err := streamLogs(ctx, exec.CommandContext(ctx, "sh", "-c", script))
+	assert.NoError(t, err)
+
+	assert.Equal(t, LogEntry{Time: time.UnixMicro(1682942400000000), Priority: 6, Message: "started"}, <-ch)
+	assert.Equal(t, LogEntry{Time: time.UnixMicro(1682942401000000), Priority: 4, Message: "slow start"}, <-ch)
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestGetJournalUsage(t *testing.T) {
+	// a journalctl printing two entries of 21 bytes each
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '{\"MESSAGE\":\"started\"}\\n{\"MESSAGE\":\"stopped\"}\\n'\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "journalctl"), []byte(script), 0o755)) //nolint:gosec // G306: the script must be executable
+	t.Setenv("PATH", dir)
+
+	size, err := GetJournalUsage("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), size)
+}
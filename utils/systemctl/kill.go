@@ -0,0 +1,46 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"syscall"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+var ErrorInvalidKillTarget = errors.New(`kill target must be one of "all", "main" or "control"`)
+
+// KillService sends the signal to processes of the unit without going through its stop logic, e.g. SIGKILL
+// for a daemon that does not react to StopService. who selects the processes, as in `systemctl kill --kill-whom=`:
+// "main" for the main process, "control" for a running ExecStartPre=/ExecStop= command, and "all" (or empty)
+// for every process of the unit.
+func KillService(name string, signal syscall.Signal, who string) error {
+	return KillServiceContext(context.Background(), name, signal, who)
+}
+
+// KillServiceContext is like KillService, but with a caller-supplied context.
+func KillServiceContext(ctx context.Context, name string, signal syscall.Signal, who string) (err error) {
+	target := dbus.Who(who)
+	switch target {
+	case "":
+		target = dbus.All
+	case dbus.All, dbus.Main, dbus.Control:
+	default:
+		return ErrorInvalidKillTarget
+	}
+
+	defer func() { audit(OperationKill, name, err) }()
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return conn.KillUnitWithTarget(ctx, name, target, int32(signal))
+}
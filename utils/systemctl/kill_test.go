@@ -0,0 +1,22 @@
+package systemctl
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKillService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "active"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, KillService("casaos.service", syscall.SIGKILL, ""))
+	assert.NoError(t, KillService("casaos.service", syscall.SIGHUP, "main"))
+	assert.ErrorIs(t, KillService("casaos.service", syscall.SIGTERM, "everyone"), ErrorInvalidKillTarget)
+	assert.ErrorIs(t, KillService("missing.service", syscall.SIGKILL, "all"), errNoSuchUnit)
+
+	assert.Equal(t, []string{"kill casaos.service all 9", "kill casaos.service main 1", "kill missing.service all 9"}, conn.calls)
+}
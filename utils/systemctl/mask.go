@@ -0,0 +1,103 @@
+package systemctl
+
+import (
+	"context"
+)
+
+// MaskService masks the unit, i.e. links it to /dev/null, so nothing can start it anymore, neither manually
+// nor as a dependency. A running instance keeps running, see MaskAndStopService.
+func MaskService(name string) error {
+	return MaskServiceContext(context.Background(), name)
+}
+
+// MaskServiceContext is like MaskService, but with a caller-supplied context.
+func MaskServiceContext(ctx context.Context, name string) (err error) {
+	defer func() { audit(OperationMask, name, err) }()
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return maskUnit(ctx, conn, name)
+}
+
+func maskUnit(ctx context.Context, conn connection, name string) error {
+	if _, err := conn.MaskUnitFilesContext(ctx, []string{name}, false, true); err != nil {
+		return err
+	}
+
+	return conn.ReloadContext(ctx)
+}
+
+// MaskAndStopService masks the unit, so nothing can start it anymore, and stops it if it is running.
+//
+// It is safe to call on a unit that is already masked and/or stopped.
+func MaskAndStopService(name string) error {
+	return MaskAndStopServiceContext(context.Background(), name)
+}
+
+// MaskAndStopServiceContext is like MaskAndStopService, but with a caller-supplied context.
+func MaskAndStopServiceContext(ctx context.Context, name string) (err error) {
+	defer func() { audit(OperationMask, name, err) }()
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if err := maskUnit(ctx, conn, name); err != nil {
+		return err
+	}
+
+	state, err := getStringProperty(ctx, conn, name, "ActiveState")
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case "inactive", "failed":
+		return nil
+	}
+
+	return stopUnit(ctx, conn, name)
+}
+
+// UnmaskService unmasks the unit. It does not start the unit, even if it is enabled.
+func UnmaskService(name string) error {
+	return UnmaskServiceContext(context.Background(), name)
+}
+
+// UnmaskServiceContext is like UnmaskService, but with a caller-supplied context.
+func UnmaskServiceContext(ctx context.Context, name string) (err error) {
+	defer func() { audit(OperationUnmask, name, err) }()
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if _, err := conn.UnmaskUnitFilesContext(ctx, []string{name}, false); err != nil {
+		return err
+	}
+
+	return conn.ReloadContext(ctx)
+}
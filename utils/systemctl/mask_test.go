@@ -0,0 +1,55 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskAndStopService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"smbd.service": {"ActiveState": "active", "UnitFileState": "enabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, MaskAndStopService("smbd.service"))
+	assert.Equal(t, []string{"mask smbd.service", "daemon-reload", "stop smbd.service"}, conn.calls)
+	assert.Equal(t, "masked", conn.units["smbd.service"]["UnitFileState"])
+	assert.Equal(t, "inactive", conn.units["smbd.service"]["ActiveState"])
+}
+
+func TestMaskAndStopServiceAlreadyMasked(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"smbd.service": {"ActiveState": "inactive", "UnitFileState": "masked"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, MaskAndStopService("smbd.service"))
+	assert.Equal(t, []string{"mask smbd.service", "daemon-reload"}, conn.calls)
+	assert.Equal(t, "masked", conn.units["smbd.service"]["UnitFileState"])
+}
+
+func TestUnmaskService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"smbd.service": {"ActiveState": "inactive", "UnitFileState": "masked"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, UnmaskService("smbd.service"))
+	assert.Equal(t, []string{"unmask smbd.service", "daemon-reload"}, conn.calls)
+	assert.Equal(t, "inactive", conn.units["smbd.service"]["ActiveState"])
+}
+
+func TestMaskService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"smbd.service": {"ActiveState": "active", "UnitFileState": "enabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, MaskService("smbd.service"))
+	assert.Equal(t, []string{"mask smbd.service", "daemon-reload"}, conn.calls)
+	assert.Equal(t, "masked", conn.units["smbd.service"]["UnitFileState"])
+	assert.Equal(t, "active", conn.units["smbd.service"]["ActiveState"])
+
+	assert.ErrorIs(t, MaskService("nonexistent.service"), errNoSuchUnit)
+}
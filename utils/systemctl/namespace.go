@@ -0,0 +1,54 @@
+package systemctl
+
+import (
+	"context"
+)
+
+// NamespaceConfig describes the namespaces a service is isolated in.
+type NamespaceConfig struct {
+	// PrivateNetwork is true if the service only sees a loopback interface, i.e. it cannot reach the host network.
+	PrivateNetwork bool
+
+	// PrivateUsers is true if the service runs in its own user namespace.
+	PrivateUsers bool
+
+	// NetworkNamespacePath is the network namespace the service joins, if any.
+	NetworkNamespacePath string
+}
+
+func GetNamespaceConfig(name string) (NamespaceConfig, error) {
+	return GetNamespaceConfigContext(context.Background(), name)
+}
+
+// GetNamespaceConfigContext is like GetNamespaceConfig, but with a caller-supplied context.
+func GetNamespaceConfigContext(ctx context.Context, name string) (NamespaceConfig, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return NamespaceConfig{}, err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitTypePropertiesContext(ctx, name, "Service")
+	if err != nil {
+		return NamespaceConfig{}, err
+	}
+
+	return namespaceConfig(properties), nil
+}
+
+func namespaceConfig(properties map[string]interface{}) NamespaceConfig {
+	privateNetwork, _ := properties["PrivateNetwork"].(bool)
+	privateUsers, _ := properties["PrivateUsers"].(bool)
+	networkNamespacePath, _ := properties["NetworkNamespacePath"].(string)
+
+	return NamespaceConfig{
+		PrivateNetwork:       privateNetwork,
+		PrivateUsers:         privateUsers,
+		NetworkNamespacePath: networkNamespacePath,
+	}
+}
@@ -0,0 +1,25 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNamespaceConfig(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos-app.service": {"PrivateNetwork": true, "PrivateUsers": false, "NetworkNamespacePath": "/run/netns/casaos"},
+		"casaos.service":     {"PrivateNetwork": false, "PrivateUsers": false, "NetworkNamespacePath": ""},
+	}).use(t)
+
+	config, err := GetNamespaceConfig("casaos-app.service")
+	assert.NoError(t, err)
+	assert.Equal(t, NamespaceConfig{PrivateNetwork: true, NetworkNamespacePath: "/run/netns/casaos"}, config)
+
+	config, err = GetNamespaceConfig("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, NamespaceConfig{}, config)
+
+	_, err = GetNamespaceConfig("nonexistent.service")
+	assert.ErrorIs(t, err, errNoSuchUnit)
+}
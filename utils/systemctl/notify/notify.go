@@ -0,0 +1,60 @@
+// Package notify lets a service report its state to systemd through the sd_notify protocol, so it can be run
+// with `Type=notify` and, with `WatchdogSec=`, be restarted by systemd if it hangs.
+//
+// All functions do nothing if the process was not started by systemd with a notification socket.
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+)
+
+// Ready tells systemd that the service finished starting up, e.g. once it accepts connections.
+func Ready() error {
+	return send(daemon.SdNotifyReady)
+}
+
+// Stopping tells systemd that the service is shutting down.
+func Stopping() error {
+	return send(daemon.SdNotifyStopping)
+}
+
+// Status sets the status line systemd shows for the service, e.g. in `systemctl status`.
+func Status(msg string) error {
+	return send("STATUS=" + msg)
+}
+
+func send(state string) error {
+	_, err := daemon.SdNotify(false, state)
+	return err
+}
+
+// StartWatchdogLoop pings the systemd watchdog at half the interval systemd expects, until ctx is done. It
+// returns right away, and does nothing if the watchdog is not enabled for the service.
+//
+// The loop keeps pinging as long as the process runs, so a service wanting hangs of its own work detected should
+// cancel ctx when that work gets stuck.
+func StartWatchdogLoop(ctx context.Context) error {
+	interval, err := daemon.SdWatchdogEnabled(false)
+	if err != nil || interval == 0 {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = send(daemon.SdNotifyWatchdog)
+			}
+		}
+	}()
+
+	return nil
+}
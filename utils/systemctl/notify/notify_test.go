@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// listen points NOTIFY_SOCKET to a socket of the test and returns it.
+func listen(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	return conn
+}
+
+func receive(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	buf := make([]byte, 1024)
+
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	conn := listen(t)
+
+	assert.NoError(t, Ready())
+	assert.Equal(t, "READY=1", receive(t, conn))
+
+	assert.NoError(t, Status("indexing 42 files"))
+	assert.Equal(t, "STATUS=indexing 42 files", receive(t, conn))
+
+	assert.NoError(t, Stopping())
+	assert.Equal(t, "STOPPING=1", receive(t, conn))
+}
+
+func TestNotifyWithoutSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	os.Unsetenv("NOTIFY_SOCKET")
+
+	assert.NoError(t, Ready())
+	assert.NoError(t, StartWatchdogLoop(context.Background()))
+}
+
+func TestStartWatchdogLoop(t *testing.T) {
+	conn := listen(t)
+
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, StartWatchdogLoop(ctx))
+	assert.Equal(t, "WATCHDOG=1", receive(t, conn))
+	assert.Equal(t, "WATCHDOG=1", receive(t, conn))
+
+	t.Setenv("WATCHDOG_USEC", "soon")
+	assert.Error(t, StartWatchdogLoop(ctx))
+}
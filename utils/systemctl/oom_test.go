@@ -0,0 +1,68 @@
+package systemctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useTempDirs(t *testing.T) {
+	originalUnitConfigDir, originalRuntimeUnitConfigDir, originalProcDir, originalCgroupDir := unitConfigDir, runtimeUnitConfigDir, procDir, cgroupDir
+	unitConfigDir, runtimeUnitConfigDir, procDir, cgroupDir = t.TempDir(), t.TempDir(), t.TempDir(), t.TempDir()
+
+	t.Cleanup(func() {
+		unitConfigDir, runtimeUnitConfigDir, procDir, cgroupDir = originalUnitConfigDir, originalRuntimeUnitConfigDir, originalProcDir, originalCgroupDir
+	})
+}
+
+func TestSetOOMScoreAdjust(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ControlGroup": "/system.slice/casaos.service", "OOMScoreAdjust": int32(0)},
+		"smbd.service":   {"ControlGroup": "", "OOMScoreAdjust": int32(0)},
+	})
+	conn.use(t)
+
+	// 1236 exited after the cgroup was read
+	assert.NoError(t, os.MkdirAll(filepath.Join(cgroupDir, "system.slice", "casaos.service"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, "system.slice", "casaos.service", "cgroup.procs"), []byte("1234\n1235\n1236\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(procDir, "1234"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(procDir, "1235"), 0o755))
+
+	assert.ErrorIs(t, SetOOMScoreAdjust("casaos.service", -1001, false), ErrorOOMScoreAdjustOutOfRange)
+	assert.ErrorIs(t, SetOOMScoreAdjust("casaos.service", 1001, true), ErrorOOMScoreAdjustOutOfRange)
+
+	assert.NoError(t, SetOOMScoreAdjust("casaos.service", -1000, false))
+	assert.Empty(t, conn.calls)
+	assertFileContent(t, filepath.Join(procDir, "1234", "oom_score_adj"), "-1000")
+	assertFileContent(t, filepath.Join(procDir, "1235", "oom_score_adj"), "-1000")
+
+	assert.NoError(t, SetOOMScoreAdjust("casaos.service", -500, true))
+	assert.Equal(t, []string{"daemon-reload"}, conn.calls)
+	assertFileContent(t, filepath.Join(procDir, "1234", "oom_score_adj"), "-500")
+	assertFileContent(t, filepath.Join(unitConfigDir, "casaos.service.d", "50-casaos-oom-score-adjust.conf"), "[Service]\nOOMScoreAdjust=-500\n")
+
+	assert.ErrorIs(t, SetOOMScoreAdjust("smbd.service", 1000, false), ErrorNotRunning)
+	assert.NoError(t, SetOOMScoreAdjust("smbd.service", 1000, true))
+}
+
+func TestGetOOMScoreAdjust(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"OOMScoreAdjust": int32(-900)},
+	}).use(t)
+
+	value, err := GetOOMScoreAdjust("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, -900, value)
+}
+
+func assertFileContent(t *testing.T, path string, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
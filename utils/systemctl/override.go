@@ -0,0 +1,98 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrorInvalidOverride = errors.New("override section and key must be non-empty and value must be a single line")
+
+// SetServiceOverride sets key in section of the unit, e.g. `MemoryMax=` in `[Service]`, in a drop-in under
+// /etc/systemd/system/<name>.d/ and reloads systemd, so the vendor unit file stays untouched. Each key is kept
+// in its own drop-in, so setting the same key again replaces the previous value. The override takes effect
+// the next time the unit starts.
+func SetServiceOverride(name, section, key, value string) error {
+	return SetServiceOverrideContext(context.Background(), name, section, key, value)
+}
+
+// SetServiceOverrideContext is like SetServiceOverride, but with a caller-supplied context.
+func SetServiceOverrideContext(ctx context.Context, name, section, key, value string) error {
+	if err := localOnly(ctx); err != nil {
+		return err
+	}
+
+	if !validOverride(section, key) || strings.ContainsAny(value, "\r\n") {
+		return ErrorInvalidOverride
+	}
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	dir, err := configDir(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	if err := writeDropIn(dir, name, overrideKey(section, key), renderDropIn(section, [][2]string{{key, value}})); err != nil {
+		return err
+	}
+
+	return conn.ReloadContext(ctx)
+}
+
+// RemoveServiceOverride removes an override set by SetServiceOverride and reloads systemd.
+// It is safe to call for an override that does not exist.
+func RemoveServiceOverride(name, section, key string) error {
+	return RemoveServiceOverrideContext(context.Background(), name, section, key)
+}
+
+// RemoveServiceOverrideContext is like RemoveServiceOverride, but with a caller-supplied context.
+func RemoveServiceOverrideContext(ctx context.Context, name, section, key string) error {
+	if err := localOnly(ctx); err != nil {
+		return err
+	}
+
+	if !validOverride(section, key) {
+		return ErrorInvalidOverride
+	}
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	dir, err := configDir(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	if err := removeDropIn(dir, name, overrideKey(section, key)); err != nil {
+		return err
+	}
+
+	return conn.ReloadContext(ctx)
+}
+
+func validOverride(section, key string) bool {
+	return section != "" && key != "" &&
+		!strings.ContainsAny(section, "[]/\r\n") && !strings.ContainsAny(key, "=/ \t\r\n")
+}
+
+func overrideKey(section, key string) string {
+	return "override-" + strings.ToLower(section) + "-" + strings.ToLower(key)
+}
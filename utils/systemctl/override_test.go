@@ -0,0 +1,49 @@
+package systemctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceOverride(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{})
+	conn.use(t)
+
+	path := filepath.Join(unitConfigDir, "smbd.service.d", "50-casaos-override-service-memorymax.conf")
+
+	assert.ErrorIs(t, SetServiceOverride("smbd.service", "", "MemoryMax", "1G"), ErrorInvalidOverride)
+	assert.ErrorIs(t, SetServiceOverride("smbd.service", "Service", "MemoryMax", "1G\nUser=root"), ErrorInvalidOverride)
+
+	assert.NoError(t, SetServiceOverride("smbd.service", "Service", "MemoryMax", "1G"))
+	assertFileContent(t, path, "[Service]\nMemoryMax=1G\n")
+
+	assert.NoError(t, SetServiceOverride("smbd.service", "Service", "MemoryMax", "2G"))
+	assertFileContent(t, path, "[Service]\nMemoryMax=2G\n")
+
+	assert.NoError(t, RemoveServiceOverride("smbd.service", "Service", "MemoryMax"))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, RemoveServiceOverride("smbd.service", "Service", "MemoryMax"))
+	assert.Equal(t, []string{"daemon-reload", "daemon-reload", "daemon-reload", "daemon-reload"}, conn.calls)
+}
+
+func TestDropInInvalidUnitName(t *testing.T) {
+	useTempDirs(t)
+
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "active"},
+	}).use(t)
+
+	assert.ErrorIs(t, SetServiceOverride("../../../tmp/x", "Service", "Nice", "5"), ErrorInvalidUnitName)
+	assert.ErrorIs(t, RemoveServiceOverride("../casaos.service", "Service", "Nice"), ErrorInvalidUnitName)
+	assert.ErrorIs(t, SetServiceEnvironment("", map[string]string{"DEBUG": "1"}), ErrorInvalidUnitName)
+	assert.ErrorIs(t, SetOOMScoreAdjust("../casaos.service", -500, true), ErrorInvalidUnitName)
+	assert.ErrorIs(t, SetLogRateLimit("../casaos.service", LogRateLimit{Interval: time.Second, Burst: 10}, true), ErrorInvalidUnitName)
+}
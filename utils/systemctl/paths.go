@@ -0,0 +1,64 @@
+package systemctl
+
+import (
+	"context"
+	"strings"
+)
+
+// ServicePaths describes where a service runs. Empty values mean systemd's defaults apply,
+// i.e. the root directory of the host.
+type ServicePaths struct {
+	// WorkingDirectory is `~` if the service runs in the home directory of its user.
+	WorkingDirectory string
+	RootDirectory    string
+	RootImage        string
+}
+
+func GetServicePaths(name string) (ServicePaths, error) {
+	return GetServicePathsContext(context.Background(), name)
+}
+
+// GetServicePathsContext is like GetServicePaths, but with a caller-supplied context.
+func GetServicePathsContext(ctx context.Context, name string) (ServicePaths, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return ServicePaths{}, err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitTypePropertiesContext(ctx, name, "Service")
+	if err != nil {
+		return ServicePaths{}, err
+	}
+
+	return servicePaths(properties)
+}
+
+func servicePaths(properties map[string]interface{}) (ServicePaths, error) {
+	workingDirectory, err := mapStringProperty(properties, "WorkingDirectory")
+	if err != nil {
+		return ServicePaths{}, err
+	}
+
+	rootDirectory, err := mapStringProperty(properties, "RootDirectory")
+	if err != nil {
+		return ServicePaths{}, err
+	}
+
+	rootImage, err := mapStringProperty(properties, "RootImage")
+	if err != nil {
+		return ServicePaths{}, err
+	}
+
+	return ServicePaths{
+		// systemd marks a working directory that may be missing (`WorkingDirectory=-/path`) with `!`
+		WorkingDirectory: strings.TrimPrefix(workingDirectory, "!"),
+		RootDirectory:    rootDirectory,
+		RootImage:        rootImage,
+	}, nil
+}
@@ -0,0 +1,31 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetServicePaths(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos-app.service": {"WorkingDirectory": "/var/lib/casaos/apps", "RootDirectory": "/var/lib/machines/app", "RootImage": ""},
+		"casaos.service":     {"WorkingDirectory": "!/var/lib/casaos", "RootDirectory": "", "RootImage": "/var/lib/casaos/root.raw"},
+		"smbd.service":       {"WorkingDirectory": "", "RootDirectory": "", "RootImage": ""},
+		"nmbd.service":       {"WorkingDirectory": []byte("/var/lib/samba")},
+	}).use(t)
+
+	paths, err := GetServicePaths("casaos-app.service")
+	assert.NoError(t, err)
+	assert.Equal(t, ServicePaths{WorkingDirectory: "/var/lib/casaos/apps", RootDirectory: "/var/lib/machines/app"}, paths)
+
+	paths, err = GetServicePaths("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, ServicePaths{WorkingDirectory: "/var/lib/casaos", RootImage: "/var/lib/casaos/root.raw"}, paths)
+
+	paths, err = GetServicePaths("smbd.service")
+	assert.NoError(t, err)
+	assert.Equal(t, ServicePaths{}, paths)
+
+	_, err = GetServicePaths("nmbd.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
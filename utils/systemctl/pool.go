@@ -0,0 +1,146 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// poolableConnection is a connection that can tell whether it is still connected.
+type poolableConnection interface {
+	connection
+	Connected() bool
+}
+
+// connectionPool holds a connection shared by all operations.
+type connectionPool struct {
+	// lock is held while using or (re)connecting conn. It is a channel rather than a mutex, so operations waiting
+	// for another one's dial to finish still respect their own context.
+	lock chan struct{}
+
+	// dial connects to systemd. ctx only bounds connecting, as the connection outlives the operation.
+	dial func(ctx context.Context) (poolableConnection, error)
+	conn poolableConnection
+}
+
+func newConnectionPool(dial func(ctx context.Context) (poolableConnection, error)) *connectionPool {
+	return &connectionPool{
+		lock: make(chan struct{}, 1),
+		dial: dial,
+	}
+}
+
+var (
+	// systemPool connects to the systemd system instance.
+	systemPool = newConnectionPool(func(context.Context) (poolableConnection, error) {
+		return dialSystem()
+	})
+
+	// userPool connects to the systemd instance of the user's session.
+	userPool = newConnectionPool(func(context.Context) (poolableConnection, error) {
+		// not bound to the context of any single operation, as the connection outlives it
+		return dbus.NewUserConnectionContext(context.Background())
+	})
+)
+
+// dialSystem connects to the systemd system instance through its private socket, which only root may use, and
+// otherwise through the system bus, where unprivileged callers may still query units and polkit decides whether
+// they may change them.
+func dialSystem() (*dbus.Conn, error) {
+	// not bound to the context of any single operation, as the connection outlives it
+	return firstConnection(
+		func() (*dbus.Conn, error) { return dbus.NewSystemdConnectionContext(context.Background()) },
+		func() (*dbus.Conn, error) { return dbus.NewSystemConnectionContext(context.Background()) },
+	)
+}
+
+// firstConnection returns the connection of the first dial that succeeds, or the errors of all of them.
+func firstConnection(dials ...func() (*dbus.Conn, error)) (*dbus.Conn, error) {
+	errs := make([]error, 0, len(dials))
+
+	for _, dial := range dials {
+		conn, err := dial()
+		if err == nil {
+			return conn, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// sharedConnection is handed out to each operation, which closes it when done, but the pooled
+// connection underneath stays open for the next operation.
+type sharedConnection struct {
+	poolableConnection
+}
+
+func (sharedConnection) Close() {}
+
+// pooledConnection returns the connection shared by all operations, either to the system instance of systemd,
+// to the instance of the user's session if ctx was derived using WithUserSession, or to systemd on another host
+// if ctx was derived using WithRemoteHost.
+func pooledConnection(ctx context.Context) (connection, error) {
+	if host := remoteHost(ctx); host != "" {
+		return remotePool(host).get(ctx)
+	}
+
+	if isUserSession(ctx) {
+		return userPool.get(ctx)
+	}
+
+	return systemPool.get(ctx)
+}
+
+// get returns the pooled connection, (re)connecting to systemd if there is none yet or it was lost,
+// e.g. because systemd was restarted.
+func (p *connectionPool) get(ctx context.Context) (connection, error) {
+	select {
+	case p.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	defer func() { <-p.lock }()
+
+	if p.conn != nil && p.conn.Connected() {
+		return sharedConnection{p.conn}, nil
+	}
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.conn = conn
+
+	return sharedConnection{conn}, nil
+}
+
+func (p *connectionPool) close() {
+	p.lock <- struct{}{}
+	defer func() { <-p.lock }()
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
+
+// Close closes the connections to systemd shared by all operations. Any later operation connects again.
+func Close() {
+	systemPool.close()
+	userPool.close()
+	closeRemotePools()
+}
@@ -0,0 +1,153 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+)
+
+// closableConnection tracks whether the fake connection was closed.
+type closableConnection struct {
+	*fakeConnection
+
+	closed bool
+}
+
+func (c *closableConnection) Close() {
+	c.closed = true
+}
+
+func (c *closableConnection) Connected() bool {
+	return !c.closed
+}
+
+func usePool(t *testing.T) *[]*closableConnection {
+	dialed := []*closableConnection{}
+
+	original := systemPool.dial
+	systemPool.dial = func(context.Context) (poolableConnection, error) {
+		conn := &closableConnection{
+			fakeConnection: newFakeConnection(map[string]map[string]interface{}{
+				"casaos.service": {"ActiveState": "active"},
+			}),
+		}
+		dialed = append(dialed, conn)
+
+		return conn, nil
+	}
+
+	t.Cleanup(func() {
+		Close()
+		systemPool.dial = original
+	})
+
+	return &dialed
+}
+
+func TestPooledConnection(t *testing.T) {
+	dialed := usePool(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			running, err := IsServiceRunning("casaos.service")
+			assert.NoError(t, err)
+			assert.True(t, running)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, *dialed, 1)
+	assert.False(t, (*dialed)[0].closed)
+
+	// reconnects once the connection was lost
+	(*dialed)[0].closed = true
+
+	_, err := IsServiceRunning("casaos.service")
+	assert.NoError(t, err)
+	assert.Len(t, *dialed, 2)
+
+	Close()
+	assert.True(t, (*dialed)[1].closed)
+
+	_, err = IsServiceRunning("casaos.service")
+	assert.NoError(t, err)
+	assert.Len(t, *dialed, 3)
+}
+
+func TestPooledConnectionCanceled(t *testing.T) {
+	dialed := usePool(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := pooledConnection(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, *dialed)
+}
+
+func TestFirstConnection(t *testing.T) {
+	errPrivate := errors.New("permission denied")
+	errBus := errors.New("no such file or directory")
+
+	conn := &dbus.Conn{}
+	dials := 0
+
+	got, err := firstConnection(
+		func() (*dbus.Conn, error) { dials++; return nil, errPrivate },
+		func() (*dbus.Conn, error) { dials++; return conn, nil },
+		func() (*dbus.Conn, error) { dials++; return nil, errBus },
+	)
+	assert.NoError(t, err)
+	assert.True(t, conn == got)
+	assert.Equal(t, 2, dials)
+
+	_, err = firstConnection(
+		func() (*dbus.Conn, error) { return nil, errPrivate },
+		func() (*dbus.Conn, error) { return nil, errBus },
+	)
+	assert.ErrorIs(t, err, errPrivate)
+	assert.ErrorIs(t, err, errBus)
+}
+
+func TestPooledConnectionDialing(t *testing.T) {
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+
+	pool := newConnectionPool(func(ctx context.Context) (poolableConnection, error) {
+		close(dialing)
+		<-release
+
+		return &closableConnection{fakeConnection: newFakeConnection(nil)}, nil
+	})
+	defer pool.close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pool.get(context.Background())
+		done <- err
+	}()
+
+	<-dialing
+
+	// waiting for another operation's dial respects the context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := pool.get(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, <-done)
+
+	_, err = pool.get(context.Background())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,31 @@
+package systemctl
+
+import "context"
+
+// ApplyPreset enables or disables the unit as its preset files (e.g. under /usr/lib/systemd/system-preset)
+// say, like `systemctl preset`.
+func ApplyPreset(name string) error {
+	return ApplyPresetContext(context.Background(), name)
+}
+
+// ApplyPresetContext is like ApplyPreset, but with a caller-supplied context.
+func ApplyPresetContext(ctx context.Context, name string) error {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	// go-systemd has no binding for Manager.PresetUnitFiles
+	return runSystemctl(ctx, "preset", "--", name)
+}
+
+// ApplyAllPresets enables or disables all installed units as the preset files say, like `systemctl preset-all`.
+func ApplyAllPresets() error {
+	return ApplyAllPresetsContext(context.Background())
+}
+
+// ApplyAllPresetsContext is like ApplyAllPresets, but with a caller-supplied context.
+func ApplyAllPresetsContext(ctx context.Context) error {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	return runSystemctl(ctx, "preset-all")
+}
@@ -0,0 +1,15 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPreset(t *testing.T) {
+	calls := useSystemctl(t)
+
+	assert.NoError(t, ApplyPreset("casaos.service"))
+	assert.NoError(t, ApplyAllPresets())
+	assert.Equal(t, []string{"preset -- casaos.service", "preset-all"}, *calls)
+}
@@ -0,0 +1,108 @@
+package systemctl
+
+import (
+	"context"
+	"strings"
+)
+
+// unitTypes maps unit name suffixes to the D-Bus interface carrying their type specific properties.
+var unitTypes = map[string]string{
+	"service":   "Service",
+	"socket":    "Socket",
+	"timer":     "Timer",
+	"mount":     "Mount",
+	"automount": "Automount",
+	"swap":      "Swap",
+	"path":      "Path",
+	"slice":     "Slice",
+	"scope":     "Scope",
+}
+
+// unitType returns the type of the unit for GetUnitTypeProperties, e.g. `Service` for `casaos.service`, or an
+// empty string for types without properties of their own, such as targets.
+func unitType(name string) string {
+	return unitTypes[name[strings.LastIndexByte(name, '.')+1:]]
+}
+
+// GetServiceProperty returns the value of a property of the unit, either a generic unit property such as
+// `FragmentPath`, or one specific to its type such as `Restart` or `ExecMainStatus` for a service. The value
+// has the Go type go-systemd decodes the D-Bus value to, e.g. string, bool, uint64 or []string.
+func GetServiceProperty(name, property string) (interface{}, error) {
+	return GetServicePropertyContext(context.Background(), name, property)
+}
+
+// GetServicePropertyContext is like GetServiceProperty, but with a caller-supplied context.
+func GetServicePropertyContext(ctx context.Context, name, property string) (interface{}, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	prop, err := conn.GetUnitPropertyContext(ctx, name, property)
+	if err != nil {
+		typ := unitType(name)
+		if typ == "" {
+			return nil, err
+		}
+
+		// not a generic unit property, so try those specific to the unit type
+		prop, err = conn.GetUnitTypePropertyContext(ctx, name, typ, property)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return prop.Value.Value(), nil
+}
+
+// GetServiceProperties returns all properties of the unit, both the generic unit properties and those specific
+// to its type, see GetServiceProperty.
+func GetServiceProperties(name string) (map[string]interface{}, error) {
+	return GetServicePropertiesContext(context.Background(), name)
+}
+
+// GetServicePropertiesContext is like GetServiceProperties, but with a caller-supplied context.
+func GetServicePropertiesContext(ctx context.Context, name string) (map[string]interface{}, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := unitType(name)
+	if typ == "" {
+		return properties, nil
+	}
+
+	typeProperties, err := conn.GetUnitTypePropertiesContext(ctx, name, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]interface{}, len(properties)+len(typeProperties))
+	for key, value := range properties {
+		merged[key] = value
+	}
+
+	for key, value := range typeProperties {
+		merged[key] = value
+	}
+
+	return merged, nil
+}
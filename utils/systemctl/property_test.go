@@ -0,0 +1,43 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitType(t *testing.T) {
+	assert.Equal(t, "Service", unitType("casaos.service"))
+	assert.Equal(t, "Timer", unitType("casaos-backup.timer"))
+	assert.Equal(t, "", unitType("multi-user.target"))
+	assert.Equal(t, "", unitType("casaos"))
+}
+
+func TestGetServiceProperty(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {
+			"FragmentPath":   "/usr/lib/systemd/system/casaos.service",
+			"Restart":        "always",
+			"ExecMainStatus": int32(0),
+		},
+	}).use(t)
+
+	value, err := GetServiceProperty("casaos.service", "FragmentPath")
+	assert.NoError(t, err)
+	assert.Equal(t, "/usr/lib/systemd/system/casaos.service", value)
+
+	value, err = GetServiceProperty("casaos.service", "ExecMainStatus")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), value)
+
+	_, err = GetServiceProperty("casaos.service", "NoSuchProperty")
+	assert.ErrorIs(t, err, errUnknownProperty)
+
+	properties, err := GetServiceProperties("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, "always", properties["Restart"])
+	assert.Equal(t, "/usr/lib/systemd/system/casaos.service", properties["FragmentPath"])
+
+	_, err = GetServiceProperties("missing.service")
+	assert.ErrorIs(t, err, errNoSuchUnit)
+}
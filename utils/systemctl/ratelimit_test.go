@@ -0,0 +1,47 @@
+package systemctl
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLogRateLimit(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"LogRateLimitIntervalUSec": uint64(30000000), "LogRateLimitBurst": uint32(10000)},
+		"smbd.service":   {"LogRateLimitIntervalUSec": uint64(0), "LogRateLimitBurst": uint32(0)},
+	}).use(t)
+
+	rl, err := GetLogRateLimit("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, LogRateLimit{Interval: 30 * time.Second, Burst: 10000}, rl)
+
+	rl, err = GetLogRateLimit("smbd.service")
+	assert.NoError(t, err)
+	assert.Equal(t, LogRateLimit{}, rl)
+}
+
+func TestSetLogRateLimit(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {},
+	})
+	conn.use(t)
+
+	assert.ErrorIs(t, SetLogRateLimit("casaos.service", LogRateLimit{Interval: -time.Second, Burst: 100}, true), ErrorNegativeLogRateLimit)
+	assert.ErrorIs(t, SetLogRateLimit("casaos.service", LogRateLimit{Interval: time.Second, Burst: -1}, true), ErrorNegativeLogRateLimit)
+	assert.Empty(t, conn.calls)
+
+	assert.NoError(t, SetLogRateLimit("casaos.service", LogRateLimit{Interval: 10 * time.Second, Burst: 500}, false))
+	assertFileContent(t, filepath.Join(runtimeUnitConfigDir, "casaos.service.d", "50-casaos-log-rate-limit.conf"),
+		"[Service]\nLogRateLimitIntervalSec=10000000us\nLogRateLimitBurst=500\n")
+
+	assert.NoError(t, SetLogRateLimit("casaos.service", LogRateLimit{Interval: 500 * time.Millisecond, Burst: 0}, true))
+	assertFileContent(t, filepath.Join(unitConfigDir, "casaos.service.d", "50-casaos-log-rate-limit.conf"),
+		"[Service]\nLogRateLimitIntervalSec=500000us\nLogRateLimitBurst=0\n")
+
+	assert.Equal(t, []string{"daemon-reload", "daemon-reload"}, conn.calls)
+}
@@ -0,0 +1,53 @@
+package systemctl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runSystemctl runs `systemctl` for operations the D-Bus API of go-systemd does not cover, with the arguments
+// built by systemctlArgs.
+var runSystemctl = func(ctx context.Context, args ...string) error {
+	args = systemctlArgs(ctx, args...)
+
+	output, err := exec.CommandContext(ctx, "systemctl", args...).CombinedOutput() //nolint:gosec // G204: arguments are not passed through a shell
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(output))
+	}
+
+	return nil
+}
+
+// systemctlArgs returns the arguments of `systemctl` for the operation, addressing the user manager in a user
+// session, and the remote host if any.
+func systemctlArgs(ctx context.Context, args ...string) []string {
+	options := []string{}
+
+	if host := remoteHost(ctx); host != "" {
+		options = append(options, "--host="+host)
+	}
+
+	if isUserSession(ctx) {
+		options = append(options, "--user")
+	}
+
+	return append(options, args...)
+}
+
+// ReexecuteDaemon serializes the state of systemd, re-executes it and restores the state, like
+// `systemctl daemon-reexec`. Unlike ReloadDaemon, this makes an upgraded systemd binary take over.
+func ReexecuteDaemon() error {
+	return ReexecuteDaemonContext(context.Background())
+}
+
+// ReexecuteDaemonContext is like ReexecuteDaemon, but with a caller-supplied context.
+func ReexecuteDaemonContext(ctx context.Context) error {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	// go-systemd has no binding for Manager.Reexecute, and systemd drops the connection before replying anyway
+	return runSystemctl(ctx, "daemon-reexec")
+}
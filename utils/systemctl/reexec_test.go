@@ -0,0 +1,42 @@
+package systemctl
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useSystemctl records the systemctl invocations instead of running them for the duration of the test.
+func useSystemctl(t *testing.T) *[]string {
+	calls := []string{}
+
+	original := runSystemctl
+	runSystemctl = func(ctx context.Context, args ...string) error {
+		calls = append(calls, strings.Join(systemctlArgs(ctx, args...), " "))
+		return nil
+	}
+
+	t.Cleanup(func() { runSystemctl = original })
+
+	return &calls
+}
+
+func TestReexecuteDaemon(t *testing.T) {
+	calls := useSystemctl(t)
+
+	assert.NoError(t, ReexecuteDaemon())
+	assert.NoError(t, ReexecuteDaemonContext(WithUserSession(context.Background())))
+	assert.Equal(t, []string{"daemon-reexec", "--user daemon-reexec"}, *calls)
+}
+
+func TestSystemctlArgs(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, []string{"daemon-reexec"}, systemctlArgs(ctx, "daemon-reexec"))
+	assert.Equal(t, []string{"--user", "daemon-reexec"}, systemctlArgs(WithUserSession(ctx), "daemon-reexec"))
+	assert.Equal(t, []string{"--host=casaos@nas.local", "daemon-reexec"}, systemctlArgs(WithRemoteHost(ctx, "casaos@nas.local"), "daemon-reexec"))
+	assert.Equal(t, []string{"--host=nas.local", "--user", "kill", "--signal=SIGHUP", "casaos.service"},
+		systemctlArgs(WithUserSession(WithRemoteHost(ctx, "nas.local")), "kill", "--signal=SIGHUP", "casaos.service"))
+}
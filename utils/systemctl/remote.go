@@ -0,0 +1,176 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+)
+
+var ErrorRemoteHost = errors.New("operation acts on local files, so it is not supported on a remote host")
+
+// NewRemoteConnection connects to systemd on another host the same way `systemctl --host` does, i.e. by
+// running `systemd-stdio-bridge` on that host through `ssh` and speaking D-Bus over its stdin/stdout.
+//
+// The local `ssh` client must be able to log into host (`[user@]hostname`) without prompting, e.g. with a key
+// loaded into an agent, as it is never allowed to prompt. The remote user needs the same privileges on that host
+// as a local caller would need for the requested operations. ctx bounds connecting only, the connection lasts
+// until Close() is called, which callers should do when done with it.
+func NewRemoteConnection(ctx context.Context, host string) (*dbus.Conn, error) {
+	return dbus.NewConnection(func() (*godbus.Conn, error) {
+		return dialRemote(ctx, "ssh", remoteBridgeArgs(host)...)
+	})
+}
+
+// remoteConnectTimeout bounds how long `ssh` tries to reach the host, in seconds, should ctx allow longer.
+const remoteConnectTimeout = "10"
+
+func remoteBridgeArgs(host string) []string {
+	return []string{
+		"-xT",
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=" + remoteConnectTimeout,
+		"--", host, "systemd-stdio-bridge",
+	}
+}
+
+func dialRemote(ctx context.Context, name string, args ...string) (*godbus.Conn, error) {
+	// not bound to ctx, as the bridge must live as long as the connection does
+	cmd := exec.Command(name, args...) //nolint:gosec // G204: the command is fixed, only the host is variable
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	transport := &commandTransport{Reader: stdout, WriteCloser: stdin, cmd: cmd}
+
+	conn, err := godbus.NewConn(transport)
+	if err != nil {
+		_ = transport.Close()
+		return nil, err
+	}
+
+	handshake := make(chan error, 1)
+	go func() {
+		// the bridge is authenticated by the remote bus already, so let it use the credentials of its own socket
+		if err := conn.Auth([]godbus.Auth{godbus.AuthExternal("")}); err != nil {
+			handshake <- err
+			return
+		}
+
+		handshake <- conn.Hello()
+	}()
+
+	select {
+	case err = <-handshake:
+	case <-ctx.Done():
+		// stopping the bridge makes the handshake fail, so it can be waited for
+		_ = transport.Close()
+		<-handshake
+
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// commandTransport carries D-Bus messages over the stdin/stdout of a command.
+type commandTransport struct {
+	io.Reader
+	io.WriteCloser
+
+	cmd *exec.Cmd
+}
+
+func (t *commandTransport) Close() error {
+	err := t.WriteCloser.Close()
+
+	_ = t.cmd.Process.Kill()
+	_ = t.cmd.Wait()
+
+	return err
+}
+
+type remoteHostKey struct{}
+
+// WithRemoteHost returns a copy of ctx that makes the operations it is passed to manage the units of systemd on
+// host, through a connection made by NewRemoteConnection and shared by all operations on that host.
+//
+//	ctx := systemctl.WithRemoteHost(context.Background(), "casaos@nas.local")
+//	err := systemctl.RestartServiceContext(ctx, "smbd.service")
+//
+// Operations that read or write files themselves, such as drop-ins, unit files and logs, cannot reach the
+// remote host's files, so they return ErrorRemoteHost instead.
+func WithRemoteHost(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, remoteHostKey{}, host)
+}
+
+func remoteHost(ctx context.Context) string {
+	host, _ := ctx.Value(remoteHostKey{}).(string)
+	return host
+}
+
+// localOnly returns ErrorRemoteHost if ctx was derived using WithRemoteHost, for operations that act on local
+// files or processes, which would not be the remote host's.
+func localOnly(ctx context.Context) error {
+	if remoteHost(ctx) != "" {
+		return ErrorRemoteHost
+	}
+
+	return nil
+}
+
+// dialRemoteHost connects to systemd on host for its pool, for at most as long as ctx allows.
+var dialRemoteHost = func(ctx context.Context, host string) (poolableConnection, error) {
+	return NewRemoteConnection(ctx, host)
+}
+
+// remotePools holds a connection pool per remote host.
+var remotePools = struct {
+	sync.Mutex
+	pools map[string]*connectionPool
+}{pools: map[string]*connectionPool{}}
+
+func remotePool(host string) *connectionPool {
+	remotePools.Lock()
+	defer remotePools.Unlock()
+
+	pool, ok := remotePools.pools[host]
+	if !ok {
+		pool = newConnectionPool(func(ctx context.Context) (poolableConnection, error) {
+			return dialRemoteHost(ctx, host)
+		})
+		remotePools.pools[host] = pool
+	}
+
+	return pool
+}
+
+func closeRemotePools() {
+	remotePools.Lock()
+	defer remotePools.Unlock()
+
+	for host, pool := range remotePools.pools {
+		pool.close()
+		delete(remotePools.pools, host)
+	}
+}
@@ -0,0 +1,108 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteBridgeArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"-xT", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", "--", "casaos@192.168.1.10", "systemd-stdio-bridge",
+	}, remoteBridgeArgs("casaos@192.168.1.10"))
+}
+
+func TestDialRemote(t *testing.T) {
+	// `cat` echoes the client's own auth handshake back, which is not a valid server reply
+	_, err := dialRemote(context.Background(), "cat")
+	assert.Error(t, err)
+
+	_, err = dialRemote(context.Background(), "/nonexistent/ssh")
+	assert.Error(t, err)
+
+	// a host that never answers, e.g. one prompting for a password
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = dialRemote(ctx, "sleep", "10")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestWithRemoteHost(t *testing.T) {
+	dialed := map[string]*closableConnection{}
+
+	original := dialRemoteHost
+	dialRemoteHost = func(ctx context.Context, host string) (poolableConnection, error) {
+		conn := &closableConnection{
+			fakeConnection: newFakeConnection(map[string]map[string]interface{}{
+				"smbd.service": {"ActiveState": map[string]string{"nas.local": "active", "backup.local": "failed"}[host]},
+			}),
+		}
+		dialed[host] = conn
+
+		return conn, nil
+	}
+
+	t.Cleanup(func() {
+		Close()
+		dialRemoteHost = original
+	})
+
+	for host, expected := range map[string]bool{"nas.local": true, "backup.local": false} {
+		ctx := WithRemoteHost(context.Background(), host)
+
+		for i := 0; i < 2; i++ {
+			running, err := IsServiceRunningContext(ctx, "smbd.service")
+			assert.NoError(t, err)
+			assert.Equal(t, expected, running, host)
+		}
+	}
+
+	assert.Len(t, dialed, 2)
+
+	Close()
+	assert.True(t, dialed["nas.local"].closed)
+	assert.True(t, dialed["backup.local"].closed)
+}
+
+func TestRemoteHostLocalOnly(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "active", "MainPID": uint32(1234), "FragmentPath": "/etc/systemd/system/casaos.service"},
+	})
+	conn.use(t)
+
+	ctx := WithRemoteHost(context.Background(), "nas.local")
+
+	_, err := configDir(ctx, false)
+	assert.ErrorIs(t, err, ErrorRemoteHost)
+
+	assert.ErrorIs(t, SetOOMScoreAdjustContext(ctx, "casaos.service", -500, false), ErrorRemoteHost)
+	assert.ErrorIs(t, SetServiceOverrideContext(ctx, "casaos.service", "Service", "Nice", "5"), ErrorRemoteHost)
+	assert.ErrorIs(t, SetServiceEnvironmentContext(ctx, "casaos.service", map[string]string{"DEBUG": "1"}), ErrorRemoteHost)
+	assert.ErrorIs(t, InstallServiceContext(ctx, ServiceDefinition{Name: "casaos-helper", ExecStart: "/usr/bin/casaos-helper"}), ErrorRemoteHost)
+	assert.ErrorIs(t, UninstallServiceContext(ctx, "casaos.service"), ErrorRemoteHost)
+	assert.ErrorIs(t, CreateTimerContext(ctx, "casaos-backup", "daily", "casaos-backup.service"), ErrorRemoteHost)
+	assert.ErrorIs(t, WriteServiceUnitContext(ctx, "casaos.service", "[Service]\nExecStart=/bin/true\n"), ErrorRemoteHost)
+
+	_, err = ReadServiceUnitContext(ctx, "casaos.service")
+	assert.ErrorIs(t, err, ErrorRemoteHost)
+
+	_, err = GetServiceLogsContext(ctx, "casaos.service", 10)
+	assert.ErrorIs(t, err, ErrorRemoteHost)
+
+	_, err = GetJournalUsageContext(ctx, "casaos.service")
+	assert.ErrorIs(t, err, ErrorRemoteHost)
+
+	_, err = StreamServiceLogs(ctx, "casaos.service")
+	assert.ErrorIs(t, err, ErrorRemoteHost)
+
+	// $PATH would be searched on this host rather than the remote one
+	assert.ErrorIs(t, StartTransientServiceContext(ctx, "casaos-fsck", []string{"fsck", "-n", "/dev/sda1"}), ErrorRemoteHost)
+
+	// nothing was done to the units, local or remote
+	assert.Empty(t, conn.calls)
+}
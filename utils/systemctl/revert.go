@@ -0,0 +1,21 @@
+package systemctl
+
+import "context"
+
+// RevertService resets the configuration of the unit to what its vendor shipped, like `systemctl revert`: drop-ins
+// under /etc/systemd/system and /run/systemd/system are removed, as is a local copy of the unit file if a vendor
+// version of it exists, and the unit is unmasked. Systemd is reloaded afterwards.
+func RevertService(name string) error {
+	return RevertServiceContext(context.Background(), name)
+}
+
+// RevertServiceContext is like RevertService, but with a caller-supplied context.
+func RevertServiceContext(ctx context.Context, name string) (err error) {
+	defer func() { audit(OperationRevert, name, err) }()
+
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	// go-systemd has no binding for Manager.RevertUnitFiles
+	return runSystemctl(ctx, "revert", "--", name)
+}
@@ -0,0 +1,16 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevertService(t *testing.T) {
+	calls := useSystemctl(t)
+
+	assert.NoError(t, RevertService("casaos.service"))
+	assert.NoError(t, RevertServiceContext(WithUserSession(context.Background()), "casaos-helper.service"))
+	assert.Equal(t, []string{"revert -- casaos.service", "--user revert -- casaos-helper.service"}, *calls)
+}
@@ -0,0 +1,55 @@
+package systemctl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type userSessionKey struct{}
+
+// WithUserSession returns a copy of ctx that makes the operations it is passed to manage the units of
+// the user's own systemd instance (`systemctl --user`) rather than those of the system.
+//
+//	ctx := systemctl.WithUserSession(context.Background())
+//	err := systemctl.StartServiceContext(ctx, "casaos-helper.service")
+func WithUserSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, userSessionKey{}, true)
+}
+
+func isUserSession(ctx context.Context) bool {
+	user, _ := ctx.Value(userSessionKey{}).(bool)
+	return user
+}
+
+// configDir returns where unit configuration is written for the systemd instance selected by ctx.
+// Runtime configuration does not survive a reboot, or the end of the user's session.
+func configDir(ctx context.Context, runtime bool) (string, error) {
+	if err := localOnly(ctx); err != nil {
+		return "", err
+	}
+
+	if !isUserSession(ctx) {
+		if runtime {
+			return runtimeUnitConfigDir, nil
+		}
+
+		return unitConfigDir, nil
+	}
+
+	if runtime {
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			return filepath.Join(dir, "systemd", "user"), nil
+		}
+
+		return filepath.Join("/run/user", strconv.Itoa(os.Getuid()), "systemd", "user"), nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "systemd", "user"), nil
+}
@@ -0,0 +1,67 @@
+package systemctl
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSession(t *testing.T) {
+	systemConnections := usePool(t)
+
+	userConnection := &closableConnection{
+		fakeConnection: newFakeConnection(map[string]map[string]interface{}{
+			"casaos-helper.service": {"ActiveState": "inactive"},
+		}),
+	}
+
+	original := userPool.dial
+	userPool.dial = func(context.Context) (poolableConnection, error) {
+		return userConnection, nil
+	}
+
+	t.Cleanup(func() { userPool.dial = original })
+
+	ctx := WithUserSession(context.Background())
+
+	assert.NoError(t, StartServiceContext(ctx, "casaos-helper.service"))
+	assert.Equal(t, []string{"start casaos-helper.service"}, userConnection.calls)
+
+	running, err := IsServiceRunningContext(ctx, "casaos-helper.service")
+	assert.NoError(t, err)
+	assert.True(t, running)
+
+	assert.Empty(t, *systemConnections)
+
+	_, err = IsServiceRunning("casaos-helper.service")
+	assert.ErrorIs(t, err, errNoSuchUnit)
+	assert.Len(t, *systemConnections, 1)
+
+	Close()
+	assert.True(t, userConnection.closed)
+}
+
+func TestConfigDir(t *testing.T) {
+	dir, err := configDir(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, unitConfigDir, dir)
+
+	dir, err = configDir(context.Background(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, runtimeUnitConfigDir, dir)
+
+	t.Setenv("XDG_CONFIG_HOME", "/home/casaos/.config")
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+
+	ctx := WithUserSession(context.Background())
+
+	dir, err = configDir(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("/home/casaos/.config", "systemd", "user"), dir)
+
+	dir, err = configDir(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("/run/user/1000", "systemd", "user"), dir)
+}
@@ -0,0 +1,124 @@
+package systemctl
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ServiceState is the state of a service as captured in a Snapshot.
+type ServiceState struct {
+	Running bool
+	Enabled bool
+}
+
+// Snapshot captures the state of all services at a point in time.
+type Snapshot struct {
+	Time     time.Time
+	Services map[string]ServiceState
+}
+
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeStarted  = "started"
+	ChangeStopped  = "stopped"
+	ChangeEnabled  = "enabled"
+	ChangeDisabled = "disabled"
+)
+
+// SnapshotChange is one difference found by DiffSnapshots, e.g. a service that was started.
+type SnapshotChange struct {
+	Name   string
+	Change string
+}
+
+// TakeSnapshot captures the state of all services, to be compared with a later snapshot using DiffSnapshots.
+func TakeSnapshot() (Snapshot, error) {
+	return TakeSnapshotContext(context.Background())
+}
+
+// TakeSnapshotContext is like TakeSnapshot, but with a caller-supplied context.
+func TakeSnapshotContext(ctx context.Context) (Snapshot, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	defer conn.Close()
+
+	files, err := conn.ListUnitFilesContext(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	units, err := conn.ListUnitsContext(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	activeStates := make(map[string]string, len(units))
+	for _, unit := range units {
+		activeStates[unit.Name] = unit.ActiveState
+	}
+
+	snapshot := Snapshot{
+		Time:     time.Now(),
+		Services: make(map[string]ServiceState, len(files)),
+	}
+
+	for _, file := range files {
+		serviceName := filepath.Base(file.Path)
+
+		snapshot.Services[serviceName] = ServiceState{
+			Running: activeStates[serviceName] == "active",
+			Enabled: file.Type == "enabled" || file.Type == "enabled-runtime",
+		}
+	}
+
+	return snapshot, nil
+}
+
+// DiffSnapshots returns what changed between the old and the new snapshot, ordered by service name.
+// A service that was added or removed is reported as such only, without its state.
+func DiffSnapshots(old, new Snapshot) []SnapshotChange {
+	changes := make([]SnapshotChange, 0)
+
+	for name, oldState := range old.Services {
+		newState, ok := new.Services[name]
+		if !ok {
+			changes = append(changes, SnapshotChange{Name: name, Change: ChangeRemoved})
+			continue
+		}
+
+		if !oldState.Running && newState.Running {
+			changes = append(changes, SnapshotChange{Name: name, Change: ChangeStarted})
+		} else if oldState.Running && !newState.Running {
+			changes = append(changes, SnapshotChange{Name: name, Change: ChangeStopped})
+		}
+
+		if !oldState.Enabled && newState.Enabled {
+			changes = append(changes, SnapshotChange{Name: name, Change: ChangeEnabled})
+		} else if oldState.Enabled && !newState.Enabled {
+			changes = append(changes, SnapshotChange{Name: name, Change: ChangeDisabled})
+		}
+	}
+
+	for name := range new.Services {
+		if _, ok := old.Services[name]; !ok {
+			changes = append(changes, SnapshotChange{Name: name, Change: ChangeAdded})
+		}
+	}
+
+	// the running change of a service comes before its enablement change
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
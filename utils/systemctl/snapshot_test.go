@@ -0,0 +1,49 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	old := Snapshot{Services: map[string]ServiceState{
+		"casaos.service":         {Running: false, Enabled: false},
+		"casaos-gateway.service": {Running: true, Enabled: true},
+		"smbd.service":           {Running: true, Enabled: true},
+		"nmbd.service":           {Running: false, Enabled: true},
+	}}
+
+	new := Snapshot{Services: map[string]ServiceState{
+		"casaos.service":         {Running: true, Enabled: true},
+		"casaos-gateway.service": {Running: true, Enabled: true},
+		"smbd.service":           {Running: false, Enabled: false},
+		"casaos-apps.service":    {Running: false, Enabled: false},
+	}}
+
+	assert.Equal(t, []SnapshotChange{
+		{Name: "casaos-apps.service", Change: ChangeAdded},
+		{Name: "casaos.service", Change: ChangeStarted},
+		{Name: "casaos.service", Change: ChangeEnabled},
+		{Name: "nmbd.service", Change: ChangeRemoved},
+		{Name: "smbd.service", Change: ChangeStopped},
+		{Name: "smbd.service", Change: ChangeDisabled},
+	}, DiffSnapshots(old, new))
+
+	assert.Empty(t, DiffSnapshots(new, new))
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "active", "UnitFileState": "enabled"},
+		"smbd.service":   {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	}).use(t)
+
+	snapshot, err := TakeSnapshot()
+	assert.NoError(t, err)
+	assert.False(t, snapshot.Time.IsZero())
+	assert.Equal(t, map[string]ServiceState{
+		"casaos.service": {Running: true, Enabled: true},
+		"smbd.service":   {Running: false, Enabled: false},
+	}, snapshot.Services)
+}
@@ -0,0 +1,204 @@
+package systemctl
+
+import (
+	"context"
+	"strings"
+)
+
+const socketSuffix = ".socket"
+
+const (
+	RunStateStopped = "stopped"
+	RunStateRunning = "running"
+
+	// RunStateSocketWaiting means the service is not running, but one of its sockets is listening, so systemd
+	// starts the service on the first connection.
+	RunStateSocketWaiting = "socket-waiting"
+)
+
+// SocketInfo describes a loaded systemd socket unit.
+type SocketInfo struct {
+	Name string
+
+	// Service is the unit the socket activates.
+	Service string
+
+	// Listen lists the addresses the socket listens on, e.g. `0.0.0.0:445` or `/run/casaos.sock`.
+	Listen []string
+
+	Running     bool
+	Connections uint32
+}
+
+func ListSockets() ([]SocketInfo, error) {
+	return ListSocketsContext(context.Background())
+}
+
+// ListSocketsContext is like ListSockets, but with a caller-supplied context.
+func ListSocketsContext(ctx context.Context) ([]SocketInfo, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	units, err := conn.ListUnitsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sockets := make([]SocketInfo, 0)
+
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, socketSuffix) {
+			continue
+		}
+
+		properties, err := conn.GetUnitTypePropertiesContext(ctx, unit.Name, "Socket")
+		if err != nil {
+			return nil, err
+		}
+
+		unitProperties, err := conn.GetUnitPropertiesContext(ctx, unit.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		socket := socketInfo(properties)
+		socket.Name = unit.Name
+		socket.Running = unit.ActiveState == "active"
+
+		if triggers, _ := unitProperties["Triggers"].([]string); len(triggers) > 0 {
+			socket.Service = triggers[0]
+		}
+
+		sockets = append(sockets, socket)
+	}
+
+	return sockets, nil
+}
+
+func socketInfo(properties map[string]interface{}) SocketInfo {
+	connections, _ := properties["NConnections"].(uint32)
+
+	socket := SocketInfo{
+		Listen:      []string{},
+		Connections: connections,
+	}
+
+	// `Listen` has the D-Bus signature `a(ss)`, pairs of type and address
+	listen, _ := properties["Listen"].([][]interface{})
+	for _, pair := range listen {
+		if len(pair) < 2 {
+			continue
+		}
+
+		if address, ok := pair[1].(string); ok {
+			socket.Listen = append(socket.Listen, address)
+		}
+	}
+
+	return socket
+}
+
+// EnableSocket enables and starts the socket unit like EnableService, so its service is started on demand. The
+// `.socket` suffix is added if missing.
+func EnableSocket(name string, opts ...EnableOption) error {
+	return EnableSocketContext(context.Background(), name, opts...)
+}
+
+// EnableSocketContext is like EnableSocket, but with a caller-supplied context.
+func EnableSocketContext(ctx context.Context, name string, opts ...EnableOption) error {
+	if !strings.HasSuffix(name, socketSuffix) {
+		name += socketSuffix
+	}
+
+	return EnableServiceContext(ctx, name, opts...)
+}
+
+// StartServiceOrSocket starts the `.socket` unit of the service if it has one, so the service itself is only
+// started on demand, and the service otherwise.
+func StartServiceOrSocket(name string) error {
+	return StartServiceOrSocketContext(context.Background(), name)
+}
+
+// StartServiceOrSocketContext is like StartServiceOrSocket, but with a caller-supplied context.
+func StartServiceOrSocketContext(ctx context.Context, name string) error {
+	socket := strings.TrimSuffix(name, ".service") + socketSuffix
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	// systemd reports units without a unit file as `not-found`
+	if state, err := getStringProperty(ctx, conn, socket, "LoadState"); err == nil && state == "loaded" {
+		name = socket
+	}
+
+	return StartServiceContext(ctx, name)
+}
+
+// GetServiceRunState returns RunStateRunning if the service is active, RunStateSocketWaiting if it is not, but
+// one of the sockets activating it is, and RunStateStopped otherwise. Unlike IsServiceRunning, this tells a
+// socket-activated service waiting for its first connection apart from one that is down.
+func GetServiceRunState(name string) (string, error) {
+	return GetServiceRunStateContext(context.Background(), name)
+}
+
+// GetServiceRunStateContext is like GetServiceRunState, but with a caller-supplied context.
+func GetServiceRunStateContext(ctx context.Context, name string) (string, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	properties, err := conn.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	activeState, err := mapStringProperty(properties, "ActiveState")
+	if err != nil {
+		return "", err
+	}
+
+	if activeState == "active" {
+		return RunStateRunning, nil
+	}
+
+	triggeredBy, _ := properties["TriggeredBy"].([]string)
+	for _, unit := range triggeredBy {
+		if !strings.HasSuffix(unit, socketSuffix) {
+			continue
+		}
+
+		state, err := getStringProperty(ctx, conn, unit, "ActiveState")
+		if err != nil {
+			return "", err
+		}
+
+		if state == "active" {
+			return RunStateSocketWaiting, nil
+		}
+	}
+
+	return RunStateStopped, nil
+}
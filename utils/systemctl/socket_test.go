@@ -0,0 +1,76 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSockets(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"cockpit.socket": {
+			"ActiveState":  "active",
+			"Triggers":     []string{"cockpit.service"},
+			"Listen":       [][]interface{}{{"Stream", "[::]:9090"}},
+			"NConnections": uint32(2),
+		},
+		"cockpit.service": {"ActiveState": "active"},
+	}).use(t)
+
+	sockets, err := ListSockets()
+	assert.NoError(t, err)
+	assert.Equal(t, []SocketInfo{{
+		Name:        "cockpit.socket",
+		Service:     "cockpit.service",
+		Listen:      []string{"[::]:9090"},
+		Running:     true,
+		Connections: 2,
+	}}, sockets)
+}
+
+func TestStartServiceOrSocket(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"cockpit.socket":  {"LoadState": "loaded", "ActiveState": "inactive"},
+		"cockpit.service": {"LoadState": "loaded", "ActiveState": "inactive"},
+		"casaos.service":  {"LoadState": "loaded", "ActiveState": "inactive"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, StartServiceOrSocket("cockpit.service"))
+	assert.NoError(t, StartServiceOrSocket("casaos.service"))
+	assert.Equal(t, []string{"start cockpit.socket", "start casaos.service"}, conn.calls)
+}
+
+func TestEnableSocket(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"cockpit.socket": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, EnableSocket("cockpit"))
+	assert.Equal(t, []string{"enable cockpit.socket", "start cockpit.socket"}, conn.calls)
+}
+
+func TestGetServiceRunState(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"cockpit.socket":  {"ActiveState": "active"},
+		"cockpit.service": {"ActiveState": "inactive", "TriggeredBy": []string{"cockpit.socket"}},
+		"sshd.socket":     {"ActiveState": "inactive"},
+		"sshd.service":    {"ActiveState": "inactive", "TriggeredBy": []string{"sshd.socket"}},
+		"casaos.service":  {"ActiveState": "active"},
+		"odd.service":     {"ActiveState": uint32(1)},
+	}).use(t)
+
+	for name, expected := range map[string]string{
+		"cockpit.service": RunStateSocketWaiting,
+		"sshd.service":    RunStateStopped,
+		"casaos.service":  RunStateRunning,
+	} {
+		state, err := GetServiceRunState(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, state, name)
+	}
+
+	_, err := GetServiceRunState("odd.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
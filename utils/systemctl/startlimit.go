@@ -0,0 +1,142 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StartLimitState describes the start rate limit of a unit: it may be started at most Burst times within Interval.
+type StartLimitState struct {
+	Interval time.Duration
+	Burst    int
+
+	// Hit is true if the limit was reached and systemd refuses to start the unit until it is cleared.
+	Hit bool
+}
+
+func GetStartLimitState(name string) (StartLimitState, error) {
+	return GetStartLimitStateContext(context.Background(), name)
+}
+
+// GetStartLimitStateContext is like GetStartLimitState, but with a caller-supplied context.
+func GetStartLimitStateContext(ctx context.Context, name string) (StartLimitState, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return StartLimitState{}, err
+	}
+
+	defer conn.Close()
+
+	unitProperties, err := conn.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return StartLimitState{}, err
+	}
+
+	serviceProperties, err := conn.GetUnitTypePropertiesContext(ctx, name, "Service")
+	if err != nil {
+		return StartLimitState{}, err
+	}
+
+	return startLimitState(unitProperties, serviceProperties)
+}
+
+func startLimitState(unitProperties, serviceProperties map[string]interface{}) (StartLimitState, error) {
+	interval, err := mapProperty[uint64](unitProperties, "StartLimitIntervalUSec")
+	if err != nil {
+		return StartLimitState{}, err
+	}
+
+	burst, err := mapProperty[uint32](unitProperties, "StartLimitBurst")
+	if err != nil {
+		return StartLimitState{}, err
+	}
+
+	result, err := mapStringProperty(serviceProperties, "Result")
+	if err != nil {
+		return StartLimitState{}, err
+	}
+
+	return StartLimitState{
+		Interval: usecToDuration(interval),
+		Burst:    int(burst),
+		Hit:      result == "start-limit-hit",
+	}, nil
+}
+
+// ClearStartLimit resets the failed state of the unit, which also resets its start rate limit counter,
+// so it can be started again right away.
+func ClearStartLimit(name string) error {
+	return ClearStartLimitContext(context.Background(), name)
+}
+
+// ClearStartLimitContext is like ClearStartLimit, but with a caller-supplied context.
+func ClearStartLimitContext(ctx context.Context, name string) error {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return conn.ResetFailedUnitContext(ctx, name)
+}
+
+// ResetFailedService resets the failed state of the unit, like `systemctl reset-failed`, so a unit refused with
+// "start request repeated too quickly" can be started again.
+func ResetFailedService(name string) error {
+	return ResetFailedServiceContext(context.Background(), name)
+}
+
+// ResetFailedServiceContext is like ResetFailedService, but with a caller-supplied context.
+func ResetFailedServiceContext(ctx context.Context, name string) error {
+	return ClearStartLimitContext(ctx, name)
+}
+
+// ResetAllFailed resets the failed state of every failed unit. All units are attempted, and the errors of those
+// that could not be reset are returned together.
+func ResetAllFailed() error {
+	return ResetAllFailedContext(context.Background())
+}
+
+// ResetAllFailedContext is like ResetAllFailed, but with a caller-supplied context.
+func ResetAllFailedContext(ctx context.Context) error {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	units, err := conn.ListUnitsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+
+	for _, unit := range units {
+		if unit.ActiveState != "failed" {
+			continue
+		}
+
+		if err := conn.ResetFailedUnitContext(ctx, unit.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", unit.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
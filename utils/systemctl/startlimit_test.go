@@ -0,0 +1,59 @@
+package systemctl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartLimitState(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {
+			"ActiveState":            "failed",
+			"Result":                 "start-limit-hit",
+			"StartLimitIntervalUSec": uint64(10000000),
+			"StartLimitBurst":        uint32(5),
+		},
+		"smbd.service": {
+			"ActiveState":            "inactive",
+			"Result":                 "success",
+			"StartLimitIntervalUSec": uint64(10000000),
+			"StartLimitBurst":        "5",
+		},
+	})
+	conn.use(t)
+
+	state, err := GetStartLimitState("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, StartLimitState{Interval: 10 * time.Second, Burst: 5, Hit: true}, state)
+
+	assert.NoError(t, ClearStartLimit("casaos.service"))
+	assert.Equal(t, []string{"reset-failed casaos.service"}, conn.calls)
+
+	state, err = GetStartLimitState("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, StartLimitState{Interval: 10 * time.Second, Burst: 5, Hit: false}, state)
+
+	_, err = GetStartLimitState("smbd.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
+
+func TestResetAllFailed(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service":         {"ActiveState": "failed"},
+		"casaos-gateway.service": {"ActiveState": "active"},
+		"smbd.service":           {"ActiveState": "failed"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, ResetAllFailed())
+	assert.Equal(t, []string{"reset-failed casaos.service", "reset-failed smbd.service"}, conn.calls)
+
+	running, err := IsServiceRunning("smbd.service")
+	assert.NoError(t, err)
+	assert.False(t, running)
+
+	assert.NoError(t, ResetFailedService("casaos-gateway.service"))
+	assert.ErrorIs(t, ResetFailedService("missing.service"), errNoSuchUnit)
+}
@@ -0,0 +1,121 @@
+package systemctl
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// ServiceStatus is a detailed view of the runtime state of a service.
+type ServiceStatus struct {
+	ActiveState string
+	SubState    string
+
+	// MainPID is zero if the service has no main process.
+	MainPID int
+
+	// MemoryCurrent is in bytes, and CPUUsage the total CPU time consumed. Both are zero if accounting is disabled.
+	MemoryCurrent uint64
+	CPUUsage      time.Duration
+
+	// ActiveEnterTimestamp is when the service last became active, zero if it never did.
+	ActiveEnterTimestamp time.Time
+
+	// Restarts counts the automatic restarts since the service was last started manually.
+	Restarts int
+}
+
+// Uptime returns how long the service has been active, or zero if it is not active.
+func (s ServiceStatus) Uptime() time.Duration {
+	if s.ActiveState != "active" || s.ActiveEnterTimestamp.IsZero() {
+		return 0
+	}
+
+	return time.Since(s.ActiveEnterTimestamp)
+}
+
+func GetServiceStatus(name string) (ServiceStatus, error) {
+	return GetServiceStatusContext(context.Background(), name)
+}
+
+// GetServiceStatusContext is like GetServiceStatus, but with a caller-supplied context.
+func GetServiceStatusContext(ctx context.Context, name string) (ServiceStatus, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	defer conn.Close()
+
+	unitProperties, err := conn.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	serviceProperties, err := conn.GetUnitTypePropertiesContext(ctx, name, "Service")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	return serviceStatus(unitProperties, serviceProperties)
+}
+
+func serviceStatus(unitProperties, serviceProperties map[string]interface{}) (ServiceStatus, error) {
+	activeState, err := mapStringProperty(unitProperties, "ActiveState")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	subState, err := mapStringProperty(unitProperties, "SubState")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	activeEnterTimestamp, err := mapProperty[uint64](unitProperties, "ActiveEnterTimestamp")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	mainPID, err := mapProperty[uint32](serviceProperties, "MainPID")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	memoryCurrent, err := mapProperty[uint64](serviceProperties, "MemoryCurrent")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	cpuUsageNSec, err := mapProperty[uint64](serviceProperties, "CPUUsageNSec")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	nRestarts, err := mapProperty[uint32](serviceProperties, "NRestarts")
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+
+	// systemd reports the maximum value when accounting is disabled
+	if memoryCurrent == math.MaxUint64 {
+		memoryCurrent = 0
+	}
+
+	if cpuUsageNSec > math.MaxInt64 {
+		cpuUsageNSec = 0
+	}
+
+	return ServiceStatus{
+		ActiveState:          activeState,
+		SubState:             subState,
+		MainPID:              int(mainPID),
+		MemoryCurrent:        memoryCurrent,
+		CPUUsage:             time.Duration(cpuUsageNSec),
+		ActiveEnterTimestamp: usecToTime(activeEnterTimestamp),
+		Restarts:             int(nRestarts),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package systemctl
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetServiceStatus(t *testing.T) {
+	activeEnter := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {
+			"ActiveState":          "active",
+			"SubState":             "running",
+			"ActiveEnterTimestamp": uint64(activeEnter.UnixMicro()),
+			"MainPID":              uint32(1234),
+			"MemoryCurrent":        uint64(32 << 20),
+			"CPUUsageNSec":         uint64(1500000000),
+			"NRestarts":            uint32(2),
+		},
+		"smbd.service": {
+			"ActiveState":          "inactive",
+			"SubState":             "dead",
+			"ActiveEnterTimestamp": uint64(0),
+			"MainPID":              uint32(0),
+			"MemoryCurrent":        uint64(math.MaxUint64),
+			"CPUUsageNSec":         uint64(math.MaxUint64),
+			"NRestarts":            uint32(0),
+		},
+		"nmbd.service": {
+			"ActiveState":          "active",
+			"SubState":             "running",
+			"ActiveEnterTimestamp": uint64(0),
+			"MainPID":              int32(1234),
+			"MemoryCurrent":        uint64(0),
+			"CPUUsageNSec":         uint64(0),
+			"NRestarts":            uint32(0),
+		},
+	}).use(t)
+
+	status, err := GetServiceStatus("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceStatus{
+		ActiveState:          "active",
+		SubState:             "running",
+		MainPID:              1234,
+		MemoryCurrent:        32 << 20,
+		CPUUsage:             1500 * time.Millisecond,
+		ActiveEnterTimestamp: time.UnixMicro(activeEnter.UnixMicro()),
+		Restarts:             2,
+	}, status)
+	assert.WithinDuration(t, time.Now(), activeEnter.Add(status.Uptime()), time.Second)
+
+	status, err = GetServiceStatus("smbd.service")
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceStatus{ActiveState: "inactive", SubState: "dead"}, status)
+	assert.Equal(t, time.Duration(0), status.Uptime())
+
+	_, err = GetServiceStatus("nmbd.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
@@ -0,0 +1,986 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errNoSuchUnit      = errors.New("no such unit")
+	errUnknownProperty = errors.New("unknown property")
+	errNotApplicable   = errors.New("job type not applicable")
+	errInvalidUnitName = errors.New("unit name is not valid")
+)
+
+// fakeConnection is an in-memory stand-in for a systemd D-Bus connection.
+type fakeConnection struct {
+	mu sync.Mutex
+
+	// units maps a unit name to its properties, including the type specific ones.
+	units map[string]map[string]interface{}
+
+	// results maps a unit name to the job result reported for it, defaulting to "done".
+	results map[string]string
+
+	// manager holds the properties of the systemd manager itself.
+	manager map[string]interface{}
+
+	calls []string
+}
+
+func newFakeConnection(units map[string]map[string]interface{}) *fakeConnection {
+	return &fakeConnection{
+		units:   units,
+		results: map[string]string{},
+	}
+}
+
+// use makes newConnection return the fake connection for the duration of the test.
+func (c *fakeConnection) use(t *testing.T) {
+	original := newConnection
+	newConnection = func(ctx context.Context) (connection, error) {
+		return c, nil
+	}
+
+	t.Cleanup(func() { newConnection = original })
+}
+
+func (c *fakeConnection) record(call string) {
+	c.calls = append(c.calls, call)
+}
+
+func (c *fakeConnection) unit(name string) (map[string]interface{}, error) {
+	// like systemd, refuse to load templates themselves
+	if isTemplate(name) {
+		return nil, errInvalidUnitName
+	}
+
+	properties, ok := c.units[name]
+	if !ok {
+		return nil, errNoSuchUnit
+	}
+
+	return properties, nil
+}
+
+func (c *fakeConnection) result(name string) string {
+	if result, ok := c.results[name]; ok {
+		return result
+	}
+
+	return ResultDone
+}
+
+func (c *fakeConnection) Close() {}
+
+func (c *fakeConnection) GetUnitPropertyContext(ctx context.Context, unit string, propertyName string) (*dbus.Property, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	properties, err := c.unit(unit)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := properties[propertyName]
+	if !ok {
+		return nil, errUnknownProperty
+	}
+
+	return &dbus.Property{Name: propertyName, Value: godbus.MakeVariant(value)}, nil
+}
+
+func (c *fakeConnection) GetUnitPropertiesContext(ctx context.Context, unit string) (map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.unit(unit)
+}
+
+func (c *fakeConnection) GetUnitTypePropertiesContext(ctx context.Context, unit string, unitType string) (map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.unit(unit)
+}
+
+func (c *fakeConnection) GetUnitTypePropertyContext(ctx context.Context, unit string, unitType string, propertyName string) (*dbus.Property, error) {
+	return c.GetUnitPropertyContext(ctx, unit, propertyName)
+}
+
+func (c *fakeConnection) ListUnitsContext(ctx context.Context) ([]dbus.UnitStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	units := make([]dbus.UnitStatus, 0, len(c.units))
+	for name, properties := range c.units {
+		description, _ := properties["Description"].(string)
+		activeState, _ := properties["ActiveState"].(string)
+		subState, _ := properties["SubState"].(string)
+
+		units = append(units, dbus.UnitStatus{
+			Name:        name,
+			Description: description,
+			LoadState:   "loaded",
+			ActiveState: activeState,
+			SubState:    subState,
+		})
+	}
+
+	sort.Slice(units, func(i, j int) bool { return units[i].Name < units[j].Name })
+
+	return units, nil
+}
+
+func (c *fakeConnection) ListUnitsByPatternsContext(ctx context.Context, states []string, patterns []string) ([]dbus.UnitStatus, error) {
+	units, err := c.ListUnitsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]dbus.UnitStatus, 0, len(units))
+	for _, unit := range units {
+		if !matchesAny(states, func(state string) bool { return state == unit.ActiveState }) {
+			continue
+		}
+
+		if !matchesAny(patterns, func(pattern string) bool {
+			matched, _ := filepath.Match(pattern, unit.Name)
+			return matched
+		}) {
+			continue
+		}
+
+		filtered = append(filtered, unit)
+	}
+
+	return filtered, nil
+}
+
+func (c *fakeConnection) ListUnitsByNamesContext(ctx context.Context, names []string) ([]dbus.UnitStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("list-units")
+
+	units := make([]dbus.UnitStatus, 0, len(names))
+	for _, name := range names {
+		properties, ok := c.units[name]
+		if !ok {
+			units = append(units, dbus.UnitStatus{Name: name, LoadState: "not-found", ActiveState: "inactive", SubState: "dead"})
+			continue
+		}
+
+		description, _ := properties["Description"].(string)
+		activeState, _ := properties["ActiveState"].(string)
+		subState, _ := properties["SubState"].(string)
+
+		loadState, ok := properties["LoadState"].(string)
+		if !ok {
+			loadState = "loaded"
+		}
+
+		// like systemd, report aliases under the name of the unit they stand for
+		id, ok := properties["Id"].(string)
+		if !ok {
+			id = name
+		}
+
+		units = append(units, dbus.UnitStatus{
+			Name:        id,
+			Description: description,
+			LoadState:   loadState,
+			ActiveState: activeState,
+			SubState:    subState,
+		})
+	}
+
+	return units, nil
+}
+
+func (c *fakeConnection) ListUnitFilesContext(ctx context.Context) ([]dbus.UnitFile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files := make([]dbus.UnitFile, 0, len(c.units))
+	for name, properties := range c.units {
+		state, ok := properties["UnitFileState"].(string)
+
+		// instances of templates only have a unit file of their own once enabled
+		if !ok && templateOf(name) != "" && !isTemplate(name) {
+			continue
+		}
+
+		files = append(files, dbus.UnitFile{Path: "/etc/systemd/system/" + name, Type: state})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return files, nil
+}
+
+func (c *fakeConnection) ListUnitFilesByPatternsContext(ctx context.Context, states []string, patterns []string) ([]dbus.UnitFile, error) {
+	files, err := c.ListUnitFilesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]dbus.UnitFile, 0, len(files))
+	for _, file := range files {
+		if !matchesAny(states, func(state string) bool { return state == file.Type }) {
+			continue
+		}
+
+		if !matchesAny(patterns, func(pattern string) bool {
+			matched, _ := filepath.Match(pattern, filepath.Base(file.Path))
+			return matched
+		}) {
+			continue
+		}
+
+		filtered = append(filtered, file)
+	}
+
+	return filtered, nil
+}
+
+// matchesAny reports whether any of the values matches, or true if there are none to match.
+func matchesAny(values []string, match func(string) bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *fakeConnection) EnableUnitFilesContext(ctx context.Context, files []string, runtime bool, force bool) (bool, []dbus.EnableUnitFileChange, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changes := []dbus.EnableUnitFileChange{}
+
+	for _, file := range files {
+		c.record("enable " + file)
+
+		properties, err := c.unit(file)
+		if err != nil {
+			return false, nil, err
+		}
+
+		// like systemd, only report the symlinks actually created
+		if properties["UnitFileState"] != "enabled" {
+			changes = append(changes, dbus.EnableUnitFileChange{
+				Type:        "symlink",
+				Filename:    "/etc/systemd/system/multi-user.target.wants/" + file,
+				Destination: "/etc/systemd/system/" + file,
+			})
+		}
+
+		properties["UnitFileState"] = "enabled"
+	}
+
+	return false, changes, nil
+}
+
+func (c *fakeConnection) DisableUnitFilesContext(ctx context.Context, files []string, runtime bool) ([]dbus.DisableUnitFileChange, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, file := range files {
+		c.record("disable " + file)
+
+		properties, err := c.unit(file)
+		if err != nil {
+			return nil, err
+		}
+
+		properties["UnitFileState"] = "disabled"
+	}
+
+	return nil, nil
+}
+
+func (c *fakeConnection) MaskUnitFilesContext(ctx context.Context, files []string, runtime bool, force bool) ([]dbus.MaskUnitFileChange, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changes := make([]dbus.MaskUnitFileChange, 0, len(files))
+
+	for _, file := range files {
+		c.record("mask " + file)
+
+		properties, err := c.unit(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if properties["UnitFileState"] != "masked" {
+			properties["UnitFileState"] = "masked"
+			changes = append(changes, dbus.MaskUnitFileChange{Type: "symlink", Filename: "/etc/systemd/system/" + file, Destination: "/dev/null"})
+		}
+	}
+
+	return changes, nil
+}
+
+func (c *fakeConnection) UnmaskUnitFilesContext(ctx context.Context, files []string, runtime bool) ([]dbus.UnmaskUnitFileChange, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changes := make([]dbus.UnmaskUnitFileChange, 0, len(files))
+
+	for _, file := range files {
+		c.record("unmask " + file)
+
+		properties, err := c.unit(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if properties["UnitFileState"] == "masked" {
+			properties["UnitFileState"] = "disabled"
+			changes = append(changes, dbus.UnmaskUnitFileChange{Type: "unlink", Filename: "/etc/systemd/system/" + file})
+		}
+	}
+
+	return changes, nil
+}
+
+func (c *fakeConnection) StartUnitContext(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("start " + name)
+
+	properties, err := c.unit(name)
+	if err != nil {
+		return 0, err
+	}
+
+	result := c.result(name)
+
+	// a unit left in failed state behaves as if its start limit was hit
+	if properties["ActiveState"] == "failed" {
+		result = ResultFailed
+	}
+
+	if result == ResultDone {
+		properties["ActiveState"] = "active"
+	}
+
+	go func() { ch <- result }()
+
+	return 1, nil
+}
+
+func (c *fakeConnection) StartTransientUnitContext(ctx context.Context, name string, mode string, properties []dbus.Property, ch chan<- string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("start-transient " + name)
+
+	if _, ok := c.units[name]; ok {
+		return 0, errors.New("unit already exists")
+	}
+
+	unit := map[string]interface{}{"ActiveState": "active"}
+	for _, property := range properties {
+		unit[property.Name] = property.Value.Value()
+	}
+
+	c.units[name] = unit
+
+	result := c.result(name)
+	go func() { ch <- result }()
+
+	return 1, nil
+}
+
+func (c *fakeConnection) StopUnitContext(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("stop " + name)
+
+	properties, err := c.unit(name)
+	if err != nil {
+		return 0, err
+	}
+
+	result := c.result(name)
+	if result == ResultDone {
+		properties["ActiveState"] = "inactive"
+	}
+
+	go func() { ch <- result }()
+
+	return 1, nil
+}
+
+func (c *fakeConnection) ReloadUnitContext(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	properties, err := c.unit(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if properties["CanReload"] != true {
+		return 0, errNotApplicable
+	}
+
+	c.record("reload " + name)
+
+	result := c.result(name)
+	go func() { ch <- result }()
+
+	return 1, nil
+}
+
+func (c *fakeConnection) RestartUnitContext(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("restart " + name)
+
+	properties, err := c.unit(name)
+	if err != nil {
+		return 0, err
+	}
+
+	result := c.result(name)
+	if result == ResultDone {
+		properties["ActiveState"] = "active"
+	}
+
+	go func() { ch <- result }()
+
+	return 1, nil
+}
+
+func (c *fakeConnection) ReloadOrRestartUnitContext(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	properties, err := c.unit(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if properties["CanReload"] == true {
+		c.record("reload " + name)
+	} else {
+		c.record("restart " + name)
+	}
+
+	result := c.result(name)
+	if result == ResultDone {
+		properties["ActiveState"] = "active"
+	}
+
+	go func() { ch <- result }()
+
+	return 1, nil
+}
+
+func (c *fakeConnection) KillUnitWithTarget(ctx context.Context, name string, target dbus.Who, signal int32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record(fmt.Sprintf("kill %s %s %d", name, target, signal))
+
+	_, err := c.unit(name)
+	return err
+}
+
+func (c *fakeConnection) FreezeUnit(ctx context.Context, unit string) error {
+	return c.setFreezerState("freeze", unit, "frozen")
+}
+
+func (c *fakeConnection) ThawUnit(ctx context.Context, unit string) error {
+	return c.setFreezerState("thaw", unit, "running")
+}
+
+func (c *fakeConnection) setFreezerState(call string, unit string, state string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record(call + " " + unit)
+
+	properties, err := c.unit(unit)
+	if err != nil {
+		return err
+	}
+
+	if properties["ActiveState"] != "active" {
+		return errNotApplicable
+	}
+
+	properties["FreezerState"] = state
+
+	return nil
+}
+
+func (c *fakeConnection) ResetFailedUnitContext(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("reset-failed " + name)
+
+	properties, err := c.unit(name)
+	if err != nil {
+		return err
+	}
+
+	if properties["ActiveState"] == "failed" {
+		properties["ActiveState"] = "inactive"
+	}
+
+	if _, ok := properties["Result"]; ok {
+		properties["Result"] = "success"
+	}
+
+	return nil
+}
+
+func (c *fakeConnection) GetManagerProperty(prop string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, ok := c.manager[prop]
+	if !ok {
+		return "", errUnknownProperty
+	}
+
+	return godbus.MakeVariant(value).String(), nil
+}
+
+func (c *fakeConnection) ReloadContext(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record("daemon-reload")
+
+	return nil
+}
+
+func TestRestartDelay(t *testing.T) {
+	properties := map[string]interface{}{
+		"Restart":     "on-failure",
+		"RestartUSec": uint64(2500000),
+	}
+
+	delay, err := restartDelay(properties)
+	assert.NoError(t, err)
+	assert.Equal(t, 2500*time.Millisecond, delay)
+
+	properties["Restart"] = "no"
+	delay, err = restartDelay(properties)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+
+	_, err = restartDelay(map[string]interface{}{"Restart": "always", "RestartUSec": "100ms"})
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+
+	_, err = restartDelay(map[string]interface{}{})
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
+
+func TestUSecToDuration(t *testing.T) {
+	assert.Equal(t, 100*time.Millisecond, usecToDuration(100000))
+	assert.Equal(t, time.Duration(math.MaxInt64), usecToDuration(math.MaxUint64))
+}
+
+func TestStartServiceAutoResetFailed(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "failed"},
+	})
+	conn.use(t)
+
+	assert.ErrorIs(t, StartService("casaos.service"), ErrorFailed)
+	assert.Equal(t, []string{"start casaos.service"}, conn.calls)
+
+	conn.calls = nil
+
+	assert.NoError(t, StartService("casaos.service", AutoResetFailed()))
+	assert.Equal(t, []string{"reset-failed casaos.service", "start casaos.service"}, conn.calls)
+	assert.Equal(t, "active", conn.units["casaos.service"]["ActiveState"])
+}
+
+func TestEnabledButNotRunning(t *testing.T) {
+	files := []dbus.UnitFile{
+		{Path: "/etc/systemd/system/casaos.service", Type: "enabled"},
+		{Path: "/etc/systemd/system/casaos-gateway.service", Type: "enabled"},
+		{Path: "/etc/systemd/system/casaos-message-bus.service", Type: "enabled-runtime"},
+		{Path: "/etc/systemd/system/casaos-app-management.service", Type: "enabled"},
+		{Path: "/lib/systemd/system/smbd.service", Type: "disabled"},
+		{Path: "/lib/systemd/system/nmbd.service", Type: "masked"},
+		{Path: "/lib/systemd/system/getty@.service", Type: "enabled"},
+	}
+
+	units := []dbus.UnitStatus{
+		{Name: "casaos.service", ActiveState: "active"},
+		{Name: "casaos-gateway.service", ActiveState: "failed"},
+		{Name: "casaos-message-bus.service", ActiveState: "inactive"},
+		{Name: "smbd.service", ActiveState: "inactive"},
+	}
+
+	assert.Equal(t, []Service{
+		{Name: "casaos-gateway.service", Enabled: true, State: StateFailed},
+		{Name: "casaos-message-bus.service", Enabled: true, State: StateStopped},
+		{Name: "casaos-app-management.service", Enabled: true, State: StateStopped},
+	}, enabledButNotRunning(files, units))
+}
+
+func TestGetServiceDocumentation(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"Documentation": []string{"https://casaos.io", "http://localhost/docs", "man:casaos(8)"}},
+		"smbd.service":   {"Documentation": []string{}},
+		"nmbd.service":   {"Documentation": "man:nmbd(8)"},
+	}).use(t)
+
+	documentation, err := GetServiceDocumentation("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://casaos.io", "http://localhost/docs", "man:casaos(8)"}, documentation)
+
+	documentation, err = GetServiceDocumentation("smbd.service")
+	assert.NoError(t, err)
+	assert.Empty(t, documentation)
+
+	_, err = GetServiceDocumentation("nmbd.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+
+	_, err = GetServiceDocumentation("nonexistent.service")
+	assert.ErrorIs(t, err, errNoSuchUnit)
+}
+
+func TestListServicesChangedSince(t *testing.T) {
+	since := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service":         {"ActiveState": "active", "StateChangeTimestamp": uint64(since.Add(time.Minute).UnixMicro())},
+		"casaos-gateway.service": {"ActiveState": "failed", "StateChangeTimestamp": uint64(since.Add(time.Second).UnixMicro())},
+		"smbd.service":           {"ActiveState": "active", "StateChangeTimestamp": uint64(since.Add(-time.Hour).UnixMicro())},
+		"nmbd.service":           {"ActiveState": "inactive", "StateChangeTimestamp": uint64(0)},
+	})
+	conn.use(t)
+
+	services, err := ListServicesChangedSince(since)
+	assert.NoError(t, err)
+	assert.Equal(t, []Service{
+		{Name: "casaos-gateway.service", Running: false, State: StateFailed},
+		{Name: "casaos.service", Running: true, State: StateRunning},
+	}, services)
+
+	// the units that could be read are still returned
+	conn.units["smbd.service"]["StateChangeTimestamp"] = uint64(since.Add(time.Hour).UnixMicro())
+	conn.units["nmbd.service"]["StateChangeTimestamp"] = "yesterday"
+	delete(conn.units["casaos-gateway.service"], "StateChangeTimestamp")
+
+	services, err = ListServicesChangedSince(since)
+	assert.Equal(t, []Service{
+		{Name: "casaos.service", Running: true, State: StateRunning},
+		{Name: "smbd.service", Running: true, State: StateRunning},
+	}, services)
+
+	var errs ServiceErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs["nmbd.service"], ErrorUnexpectedPropertyType)
+	assert.ErrorIs(t, errs["casaos-gateway.service"], errUnknownProperty)
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	ctx, cancel := withDefaultTimeout(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(defaultTimeout), deadline, time.Second)
+
+	parent, parentCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer parentCancel()
+
+	expected, _ := parent.Deadline()
+
+	ctx, cancel = withDefaultTimeout(parent)
+	defer cancel()
+
+	deadline, ok = ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, expected, deadline)
+
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func TestListServicesResult(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service":         {"ActiveState": "active", "UnitFileState": "enabled", "Description": "CasaOS Main Service"},
+		"casaos-gateway.service": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+		"casaos-worker@.service": {},
+		"broken.service":         {"LoadState": "bad-setting", "ActiveState": "inactive"},
+	})
+	conn.use(t)
+
+	result, err := ListServicesResult("*")
+	assert.NoError(t, err)
+	assert.Equal(t, []Service{
+		{Name: "broken.service", Running: false, State: StateStopped},
+		{Name: "casaos-gateway.service", Running: false, State: StateStopped},
+		{Name: "casaos-worker@.service", Running: false, State: StateUnknown},
+		{Name: "casaos.service", Running: true, Enabled: true, Description: "CasaOS Main Service", State: StateRunning},
+	}, result.Services)
+	assert.Len(t, result.Errors, 1)
+	assert.ErrorIs(t, result.Errors["broken.service"], ErrorNotLoaded)
+
+	// the states of all units are fetched at once
+	assert.Equal(t, []string{"list-units"}, conn.calls)
+
+	services, err := ListServices("*")
+	assert.NoError(t, err)
+	assert.Equal(t, result.Services, services)
+}
+
+func TestListServicesResultAliasesAndMasked(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"sshd.service": {
+			"Id":            "ssh.service",
+			"LoadState":     "loaded",
+			"ActiveState":   "active",
+			"Description":   "OpenBSD Secure Shell server",
+			"UnitFileState": "alias",
+		},
+		"nmbd.service": {"LoadState": "masked", "ActiveState": "inactive", "UnitFileState": "masked"},
+	}).use(t)
+
+	result, err := ListServicesResult("*")
+	assert.NoError(t, err)
+	assert.Equal(t, []Service{
+		{Name: "nmbd.service", Running: false, State: StateStopped},
+		{Name: "sshd.service", Running: true, Description: "OpenBSD Secure Shell server", State: StateRunning},
+	}, result.Services)
+
+	// aliases are resolved, and being masked is not an error
+	assert.Empty(t, result.Errors)
+}
+
+func TestListServicesWithMainPID(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "active", "MainPID": uint32(1234)},
+		"smbd.service":   {"ActiveState": "inactive", "MainPID": uint32(0)},
+	})
+	conn.use(t)
+
+	services, err := ListServices("*", ListServicesWithMainPID())
+	assert.NoError(t, err)
+	assert.Equal(t, []Service{
+		{Name: "casaos.service", Running: true, State: StateRunning, MainPID: 1234},
+		{Name: "smbd.service", Running: false, State: StateStopped},
+	}, services)
+}
+
+func TestListServicesFiltered(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service":              {"ActiveState": "active", "UnitFileState": "enabled"},
+		"casaos-gateway.service":      {"ActiveState": "failed", "UnitFileState": "enabled"},
+		"casaos-backup.timer":         {"ActiveState": "active", "UnitFileState": "enabled"},
+		"casaos.socket":               {"ActiveState": "inactive", "UnitFileState": "disabled"},
+		"casaos-worker@.service":      {},
+		"casaos-worker@media.service": {"ActiveState": "active"},
+		"smbd.service":                {"ActiveState": "inactive", "UnitFileState": "disabled"},
+		"getty@.service":              {"UnitFileState": "enabled"},
+		"getty@tty1.service":          {"ActiveState": "active"},
+	}).use(t)
+
+	names := func(opts ...ListServicesOption) []string {
+		services, err := ListServices("*", opts...)
+		assert.NoError(t, err)
+
+		names := make([]string, 0, len(services))
+		for _, service := range services {
+			names = append(names, service.Name)
+		}
+
+		return names
+	}
+
+	assert.Equal(t, []string{"casaos-backup.timer", "casaos-worker@media.service", "casaos.service", "getty@tty1.service"}, names(ListServicesOnlyRunning()))
+	assert.Equal(t, []string{"casaos-backup.timer", "casaos-gateway.service", "casaos.service", "getty@.service"}, names(ListServicesOnlyEnabled()))
+	assert.Equal(t, []string{"casaos-gateway.service"}, names(ListServicesOnlyFailed()))
+	assert.Equal(t, []string{"casaos-backup.timer", "casaos.socket"}, names(ListServicesOfType("timer", "socket")))
+	assert.Equal(t, []string{"casaos-worker@media.service", "casaos.service", "getty@tty1.service"},
+		names(ListServicesOnlyRunning(), ListServicesOfType("service")))
+	assert.Equal(t, []string{"casaos-backup.timer", "casaos.service"}, names(ListServicesOnlyRunning(), ListServicesOnlyEnabled()))
+}
+
+func TestState(t *testing.T) {
+	assert.Equal(t, StateRunning, serviceState("active"))
+	assert.Equal(t, StateRunning, serviceState("reloading"))
+	assert.Equal(t, StateStopped, serviceState("inactive"))
+	assert.Equal(t, StateStopped, serviceState("deactivating"))
+	assert.Equal(t, StateFailed, serviceState("failed"))
+	assert.Equal(t, StateUnknown, serviceState(""))
+}
+
+func TestEnableAndStart(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, EnableAndStart("casaos.service"))
+	assert.Equal(t, []string{"enable casaos.service", "daemon-reload", "start casaos.service"}, conn.calls)
+	assert.Equal(t, "enabled", conn.units["casaos.service"]["UnitFileState"])
+	assert.Equal(t, "active", conn.units["casaos.service"]["ActiveState"])
+}
+
+func TestEnableAndStartRollback(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.results["casaos.service"] = ResultFailed
+	conn.use(t)
+
+	assert.ErrorIs(t, EnableAndStart("casaos.service"), ErrorFailed)
+	assert.Equal(t, []string{"enable casaos.service", "daemon-reload", "start casaos.service", "disable casaos.service", "daemon-reload"}, conn.calls)
+	assert.Equal(t, "disabled", conn.units["casaos.service"]["UnitFileState"])
+	assert.Equal(t, "inactive", conn.units["casaos.service"]["ActiveState"])
+}
+
+func TestEnableAndStartAlreadyEnabled(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive", "UnitFileState": "enabled"},
+	})
+	conn.results["casaos.service"] = ResultFailed
+	conn.use(t)
+
+	// a unit enabled before is left enabled when it fails to start
+	assert.ErrorIs(t, EnableAndStart("casaos.service"), ErrorFailed)
+	assert.Equal(t, []string{"enable casaos.service", "daemon-reload", "start casaos.service"}, conn.calls)
+	assert.Equal(t, "enabled", conn.units["casaos.service"]["UnitFileState"])
+}
+
+func TestUnexpectedPropertyType(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": uint32(1), "UnitFileState": []string{"enabled"}},
+	}).use(t)
+
+	running, err := IsServiceRunning("casaos.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+	assert.ErrorContains(t, err, "ActiveState")
+	assert.False(t, running)
+
+	_, err = IsServiceEnabled("casaos.service")
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
+
+func TestReloadService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"nginx.service":  {"ActiveState": "active", "CanReload": true},
+		"casaos.service": {"ActiveState": "active", "CanReload": false},
+	})
+	conn.use(t)
+
+	assert.NoError(t, ReloadService("nginx.service"))
+	assert.ErrorIs(t, ReloadService("casaos.service"), errNotApplicable)
+
+	assert.NoError(t, ReloadOrRestartService("nginx.service"))
+	assert.NoError(t, ReloadOrRestartService("casaos.service"))
+
+	assert.Equal(t, []string{"reload nginx.service", "reload nginx.service", "restart casaos.service"}, conn.calls)
+}
+
+func TestContextCanceled(t *testing.T) {
+	original := newConnection
+	newConnection = func(ctx context.Context) (connection, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	t.Cleanup(func() { newConnection = original })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, StartServiceContext(ctx, "casaos.service"), context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := IsServiceRunningContext(ctx, "casaos.service")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestJobError(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive"},
+		"smbd.service":   {"ActiveState": "inactive"},
+	})
+	conn.results["casaos.service"] = ResultDependency
+	conn.results["smbd.service"] = "frobnicated"
+	conn.use(t)
+
+	err := StartService("casaos.service")
+	assert.ErrorIs(t, err, ErrorDependency)
+	assert.Equal(t, "casaos.service: "+ErrorDependency.Error(), err.Error())
+
+	var jobErr *JobError
+	assert.True(t, errors.As(err, &jobErr))
+	assert.Equal(t, &JobError{Unit: "casaos.service", Result: ResultDependency, Err: ErrorDependency}, jobErr)
+
+	err = StopService("smbd.service")
+	assert.ErrorIs(t, err, ErrorUnknown)
+	assert.True(t, errors.As(err, &jobErr))
+	assert.Equal(t, "frobnicated", jobErr.Result)
+
+	// errors submitting the job are passed through
+	err = StartService("missing.service")
+	assert.ErrorIs(t, err, errNoSuchUnit)
+	assert.False(t, errors.As(err, &jobErr))
+}
+
+func TestEnableService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+		"smbd.service":   {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, EnableService("casaos.service", EnableOnly()))
+	assert.NoError(t, EnableService("smbd.service", EnableNow()))
+	assert.Equal(t, []string{"enable casaos.service", "enable smbd.service", "start smbd.service"}, conn.calls)
+	assert.Equal(t, "inactive", conn.units["casaos.service"]["ActiveState"])
+}
+
+func TestRestartService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, RestartService("casaos.service"))
+	assert.Equal(t, []string{"restart casaos.service"}, conn.calls)
+	assert.Equal(t, "active", conn.units["casaos.service"]["ActiveState"])
+
+	conn.results["casaos.service"] = ResultFailed
+	assert.ErrorIs(t, RestartService("casaos.service"), ErrorFailed)
+}
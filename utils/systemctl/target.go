@@ -0,0 +1,132 @@
+package systemctl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+const targetSuffix = ".target"
+
+// TargetInfo describes a loaded systemd target unit.
+//
+// Targets group other units, so StartService, StopService and EnableService can be called with a target
+// name (e.g. `casaos-apps.target`) to manage all of its wanted units at once.
+type TargetInfo struct {
+	Name        string
+	Description string
+	ActiveState string
+	Running     bool
+}
+
+func ListTargets() ([]TargetInfo, error) {
+	return ListTargetsContext(context.Background())
+}
+
+// ListTargetsContext is like ListTargets, but with a caller-supplied context.
+func ListTargetsContext(ctx context.Context) ([]TargetInfo, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	units, err := conn.ListUnitsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return targetsFromUnits(units), nil
+}
+
+func targetsFromUnits(units []dbus.UnitStatus) []TargetInfo {
+	targets := make([]TargetInfo, 0)
+
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, targetSuffix) {
+			continue
+		}
+
+		targets = append(targets, TargetInfo{
+			Name:        unit.Name,
+			Description: unit.Description,
+			ActiveState: unit.ActiveState,
+			Running:     unit.ActiveState == "active",
+		})
+	}
+
+	return targets
+}
+
+// maxExpandDepth bounds how many levels of dependencies ExpandTarget follows.
+const maxExpandDepth = 8
+
+// ExpandTarget returns the units that starting the target would activate, following `Wants=` and `Requires=`
+// recursively, up to maxExpandDepth levels deep.
+func ExpandTarget(name string) ([]string, error) {
+	return ExpandTargetContext(context.Background(), name)
+}
+
+// ExpandTargetContext is like ExpandTarget, but with a caller-supplied context.
+func ExpandTargetContext(ctx context.Context, name string) ([]string, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	return expandUnit(name, maxExpandDepth, func(unit string) ([]string, error) {
+		properties, err := conn.GetUnitPropertiesContext(ctx, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		wants, _ := properties["Wants"].([]string)
+		requires, _ := properties["Requires"].([]string)
+
+		return append(wants, requires...), nil
+	})
+}
+
+// expandUnit walks the dependencies of name breadth first, visiting each unit only once so cycles terminate.
+func expandUnit(name string, depth int, dependencies func(unit string) ([]string, error)) ([]string, error) {
+	visited := map[string]bool{name: true}
+	units := make([]string, 0)
+
+	level := []string{name}
+	for i := 0; i < depth && len(level) > 0; i++ {
+		next := make([]string, 0)
+
+		for _, unit := range level {
+			deps, err := dependencies(unit)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, dep := range deps {
+				if visited[dep] {
+					continue
+				}
+
+				visited[dep] = true
+				units = append(units, dep)
+				next = append(next, dep)
+			}
+		}
+
+		level = next
+	}
+
+	return units, nil
+}
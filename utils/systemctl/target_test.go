@@ -0,0 +1,64 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetsFromUnits(t *testing.T) {
+	units := []dbus.UnitStatus{
+		{Name: "casaos.service", ActiveState: "active"},
+		{Name: "casaos-apps.target", Description: "CasaOS Apps", ActiveState: "active"},
+		{Name: "multi-user.target", Description: "Multi-User System", ActiveState: "active"},
+		{Name: "rescue.target", Description: "Rescue Mode", ActiveState: "inactive"},
+		{Name: "casaos.socket", ActiveState: "inactive"},
+	}
+
+	assert.Equal(t, []TargetInfo{
+		{Name: "casaos-apps.target", Description: "CasaOS Apps", ActiveState: "active", Running: true},
+		{Name: "multi-user.target", Description: "Multi-User System", ActiveState: "active", Running: true},
+		{Name: "rescue.target", Description: "Rescue Mode", ActiveState: "inactive", Running: false},
+	}, targetsFromUnits(units))
+}
+
+func TestExpandUnit(t *testing.T) {
+	graph := map[string][]string{
+		"casaos-apps.target":     {"casaos-app-a.service", "casaos-app-b.service", "casaos-base.target"},
+		"casaos-app-a.service":   {"casaos-gateway.service"},
+		"casaos-app-b.service":   {"casaos-gateway.service"},
+		"casaos-base.target":     {"casaos-gateway.service", "casaos-apps.target"}, // cycle
+		"casaos-gateway.service": {"network-online.target"},
+	}
+
+	dependencies := func(unit string) ([]string, error) {
+		return graph[unit], nil
+	}
+
+	units, err := expandUnit("casaos-apps.target", maxExpandDepth, dependencies)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"casaos-app-a.service",
+		"casaos-app-b.service",
+		"casaos-base.target",
+		"casaos-gateway.service",
+		"network-online.target",
+	}, units)
+
+	units, err = expandUnit("casaos-apps.target", 1, dependencies)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"casaos-app-a.service", "casaos-app-b.service", "casaos-base.target"}, units)
+}
+
+func TestExpandTarget(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos-apps.target":   {"Wants": []string{"casaos-app-a.service"}, "Requires": []string{"casaos.service"}},
+		"casaos-app-a.service": {"Wants": []string{}, "Requires": []string{"casaos.service"}},
+		"casaos.service":       {"Wants": []string{"casaos-apps.target"}, "Requires": []string{}},
+	}).use(t)
+
+	units, err := ExpandTarget("casaos-apps.target")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"casaos-app-a.service", "casaos.service"}, units)
+}
@@ -0,0 +1,46 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrorInvalidTemplateInstance = errors.New("template must end with `@` or `@.<type>`, and instance must be a valid unit name part")
+
+// validInstanceChars are the characters systemd allows in the instance part of a unit name. Arbitrary strings,
+// such as paths, must be escaped first, e.g. with `systemd-escape`.
+const validInstanceChars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ:-_.\\"
+
+// TemplateInstanceName returns the name of the instance of the template unit, e.g. `casaos-worker@media.service`
+// for the template `casaos-worker@` (or `casaos-worker@.service`) and the instance `media`.
+func TemplateInstanceName(template, instance string) (string, error) {
+	if instance == "" || strings.Trim(instance, validInstanceChars) != "" {
+		return "", ErrorInvalidTemplateInstance
+	}
+
+	switch {
+	case strings.HasSuffix(template, "@") && len(template) > 1:
+		return template + instance + ".service", nil
+	case isTemplate(template) && !strings.HasPrefix(template, "@"):
+		return strings.Replace(template, "@.", "@"+instance+".", 1), nil
+	default:
+		return "", ErrorInvalidTemplateInstance
+	}
+}
+
+// EnableTemplateInstance enables and starts an instance of the template unit, like EnableService, e.g.
+// `casaos-worker@media.service` for the template `casaos-worker@` and the instance `media`.
+func EnableTemplateInstance(template, instance string, opts ...EnableOption) error {
+	return EnableTemplateInstanceContext(context.Background(), template, instance, opts...)
+}
+
+// EnableTemplateInstanceContext is like EnableTemplateInstance, but with a caller-supplied context.
+func EnableTemplateInstanceContext(ctx context.Context, template, instance string, opts ...EnableOption) error {
+	name, err := TemplateInstanceName(template, instance)
+	if err != nil {
+		return err
+	}
+
+	return EnableServiceContext(ctx, name, opts...)
+}
@@ -0,0 +1,60 @@
+package systemctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateInstanceName(t *testing.T) {
+	for _, c := range []struct {
+		template, instance, expected string
+	}{
+		{"casaos-worker@", "media", "casaos-worker@media.service"},
+		{"casaos-worker@.service", "media", "casaos-worker@media.service"},
+		{"casaos-backup@.timer", "daily", "casaos-backup@daily.timer"},
+		{"casaos-worker@", "media\\x2dserver", "casaos-worker@media\\x2dserver.service"},
+	} {
+		name, err := TemplateInstanceName(c.template, c.instance)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, name)
+	}
+
+	for _, c := range [][2]string{
+		{"casaos-worker", "media"},
+		{"casaos-worker.service", "media"},
+		{"@", "media"},
+		{"casaos-worker@", ""},
+		{"casaos-worker@", "/DATA/Media"},
+	} {
+		_, err := TemplateInstanceName(c[0], c[1])
+		assert.ErrorIs(t, err, ErrorInvalidTemplateInstance, c)
+	}
+}
+
+func TestEnableTemplateInstance(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos-worker@media.service": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, EnableTemplateInstance("casaos-worker@", "media"))
+	assert.Equal(t, []string{"enable casaos-worker@media.service", "start casaos-worker@media.service"}, conn.calls)
+}
+
+func TestListServicesTemplateInstances(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos-worker@.service":      {},
+		"casaos-worker@media.service": {"ActiveState": "active"},
+		"casaos-worker@sync.service":  {"ActiveState": "failed"},
+		"casaos.service":              {"ActiveState": "active"},
+	}).use(t)
+
+	services, err := ListServices("casaos-worker@*")
+	assert.NoError(t, err)
+	assert.Equal(t, []Service{
+		{Name: "casaos-worker@.service", Running: false, State: StateUnknown},
+		{Name: "casaos-worker@media.service", Running: true, State: StateRunning},
+		{Name: "casaos-worker@sync.service", Running: false, State: StateFailed},
+	}, services)
+}
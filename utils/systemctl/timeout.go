@@ -0,0 +1,70 @@
+package systemctl
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// operationTimeoutNanos and jobTimeoutNanos hold the process-wide defaults, zero meaning unset.
+	operationTimeoutNanos atomic.Int64
+	jobTimeoutNanos       atomic.Int64
+)
+
+type (
+	timeoutKey    struct{}
+	jobTimeoutKey struct{}
+)
+
+// SetDefaultTimeout changes how long each operation may take, 30 seconds unless set, for operations whose
+// context carries neither a deadline nor a timeout set by WithTimeout. A non-positive value restores the default.
+func SetDefaultTimeout(timeout time.Duration) {
+	operationTimeoutNanos.Store(int64(timeout))
+}
+
+// SetDefaultJobTimeout bounds how long StartService, StopService and the other operations running a systemd
+// job wait for it to finish, for operations whose context carries no job timeout set by WithJobTimeout. By
+// default they wait until systemd reports the result, which is itself bounded by `JobTimeoutSec=` of the
+// unit, or until the operation's context is done, whichever comes first. A non-positive value restores the
+// default.
+func SetDefaultJobTimeout(timeout time.Duration) {
+	jobTimeoutNanos.Store(int64(timeout))
+}
+
+// WithTimeout returns a copy of ctx that makes each operation it is passed to take at most timeout, overriding
+// SetDefaultTimeout. Unlike a deadline set with context.WithTimeout, the timeout starts anew with each operation.
+// A deadline ctx already has, e.g. that of an HTTP request, still applies if it is earlier.
+//
+//	ctx := systemctl.WithTimeout(context.Background(), 5*time.Second)
+//	running, err := systemctl.IsServiceRunningContext(ctx, "casaos.service")
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// WithJobTimeout returns a copy of ctx that makes the operations it is passed to wait at most timeout for their
+// systemd job to finish, overriding SetDefaultJobTimeout. ErrorTimeout is returned once it expires, while the
+// job itself carries on.
+func WithJobTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, jobTimeoutKey{}, timeout)
+}
+
+func operationTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return timeout
+	}
+
+	if timeout := time.Duration(operationTimeoutNanos.Load()); timeout > 0 {
+		return timeout
+	}
+
+	return defaultTimeout
+}
+
+func jobTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(jobTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return timeout
+	}
+
+	return time.Duration(jobTimeoutNanos.Load())
+}
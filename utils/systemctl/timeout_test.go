@@ -0,0 +1,118 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationTimeout(t *testing.T) {
+	t.Cleanup(func() { SetDefaultTimeout(0) })
+
+	assert.Equal(t, defaultTimeout, operationTimeout(context.Background()))
+
+	SetDefaultTimeout(time.Minute)
+	assert.Equal(t, time.Minute, operationTimeout(context.Background()))
+
+	ctx := WithTimeout(context.Background(), 5*time.Second)
+	assert.Equal(t, 5*time.Second, operationTimeout(ctx))
+
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+
+	SetDefaultTimeout(-1)
+	assert.Equal(t, defaultTimeout, operationTimeout(context.Background()))
+}
+
+func TestWithTimeoutAndDeadline(t *testing.T) {
+	// the timeout applies within a longer deadline, e.g. that of an HTTP request
+	parent, parentCancel := context.WithTimeout(context.Background(), time.Hour)
+	defer parentCancel()
+
+	ctx, cancel := withDefaultTimeout(WithTimeout(parent, 5*time.Second))
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+
+	// while an earlier deadline still applies
+	parent, parentCancel = context.WithTimeout(context.Background(), time.Second)
+	defer parentCancel()
+
+	expected, _ := parent.Deadline()
+
+	ctx, cancel = withDefaultTimeout(WithTimeout(parent, time.Hour))
+	defer cancel()
+
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, expected, deadline)
+
+	// the default timeout does not shorten a deadline
+	SetDefaultTimeout(time.Second)
+	t.Cleanup(func() { SetDefaultTimeout(0) })
+
+	parent, parentCancel = context.WithTimeout(context.Background(), time.Hour)
+	defer parentCancel()
+
+	expected, _ = parent.Deadline()
+
+	ctx, cancel = withDefaultTimeout(parent)
+	defer cancel()
+
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, expected, deadline)
+}
+
+func TestJobTimeout(t *testing.T) {
+	t.Cleanup(func() { SetDefaultJobTimeout(0) })
+
+	// a job systemd never reports a result for
+	stuck := func(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+		return 1, nil
+	}
+
+	assert.Equal(t, time.Duration(0), jobTimeout(context.Background()))
+
+	SetDefaultJobTimeout(10 * time.Millisecond)
+	assert.ErrorIs(t, runJob(context.Background(), stuck, "casaos.service"), ErrorTimeout)
+
+	SetDefaultJobTimeout(time.Hour)
+	ctx := WithJobTimeout(context.Background(), 10*time.Millisecond)
+	assert.ErrorIs(t, runJob(ctx, stuck, "casaos.service"), ErrorTimeout)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "inactive"},
+	})
+	conn.use(t)
+
+	assert.NoError(t, StartServiceContext(ctx, "casaos.service"))
+}
+
+func TestJobCanceled(t *testing.T) {
+	// a job systemd never reports a result for
+	stuck := func(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+		return 1, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	err := runJob(ctx, stuck, "casaos.service")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	var jobErr *JobError
+	assert.ErrorAs(t, err, &jobErr)
+	assert.Equal(t, "casaos.service", jobErr.Unit)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, runJob(ctx, stuck, "casaos.service"), context.DeadlineExceeded)
+}
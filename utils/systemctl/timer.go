@@ -0,0 +1,221 @@
+package systemctl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const timerSuffix = ".timer"
+
+var ErrorInvalidTimer = errors.New("timer requires a valid name and target unit")
+
+// TimerInfo describes a loaded systemd timer unit.
+type TimerInfo struct {
+	Name string
+
+	// Unit is the unit the timer activates when it elapses.
+	Unit string
+
+	// Schedule is the `OnCalendar=` expression of the timer, empty for monotonic timers.
+	Schedule string
+
+	// NextElapse and LastTrigger are zero if the timer is not scheduled or has never triggered.
+	NextElapse  time.Time
+	LastTrigger time.Time
+
+	Running bool
+}
+
+func ListTimers() ([]TimerInfo, error) {
+	return ListTimersContext(context.Background())
+}
+
+// ListTimersContext is like ListTimers, but with a caller-supplied context.
+func ListTimersContext(ctx context.Context) ([]TimerInfo, error) {
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	units, err := conn.ListUnitsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timers := make([]TimerInfo, 0)
+
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, timerSuffix) {
+			continue
+		}
+
+		properties, err := conn.GetUnitTypePropertiesContext(ctx, unit.Name, "Timer")
+		if err != nil {
+			return nil, err
+		}
+
+		timer, err := timerInfo(properties)
+		if err != nil {
+			return nil, err
+		}
+
+		timer.Name = unit.Name
+		timer.Running = unit.ActiveState == "active"
+
+		timers = append(timers, timer)
+	}
+
+	return timers, nil
+}
+
+func timerInfo(properties map[string]interface{}) (TimerInfo, error) {
+	unit, err := mapStringProperty(properties, "Unit")
+	if err != nil {
+		return TimerInfo{}, err
+	}
+
+	nextElapse, err := mapProperty[uint64](properties, "NextElapseUSecRealtime")
+	if err != nil {
+		return TimerInfo{}, err
+	}
+
+	lastTrigger, err := mapProperty[uint64](properties, "LastTriggerUSec")
+	if err != nil {
+		return TimerInfo{}, err
+	}
+
+	timer := TimerInfo{
+		Unit:        unit,
+		NextElapse:  usecToTime(nextElapse),
+		LastTrigger: usecToTime(lastTrigger),
+	}
+
+	// `TimersCalendar` has the D-Bus signature `a(sst)`
+	calendars, err := mapProperty[[][]interface{}](properties, "TimersCalendar")
+	if err != nil {
+		return TimerInfo{}, err
+	}
+
+	for _, calendar := range calendars {
+		if len(calendar) < 2 || calendar[0] != "OnCalendar" {
+			continue
+		}
+
+		schedule, ok := calendar[1].(string)
+		if !ok {
+			return TimerInfo{}, fmt.Errorf("%w: TimersCalendar has an expression of type %T instead of a string", ErrorUnexpectedPropertyType, calendar[1])
+		}
+
+		timer.Schedule = schedule
+		break
+	}
+
+	return timer, nil
+}
+
+// validateCalendar checks the `OnCalendar=` expression with `systemd-analyze calendar`.
+var validateCalendar = func(ctx context.Context, schedule string) error {
+	output, err := exec.CommandContext(ctx, "systemd-analyze", "calendar", "--", schedule).CombinedOutput() //nolint:gosec // G204: arguments are not passed through a shell
+	if err != nil {
+		return fmt.Errorf("invalid calendar expression %q: %s", schedule, bytes.TrimSpace(output))
+	}
+
+	return nil
+}
+
+// CreateTimer installs a timer under /etc/systemd/system that activates the target unit on the given
+// `OnCalendar=` schedule, e.g. `daily` or `Sun *-*-* 03:00:00`, then enables and starts it. Runs missed while
+// the system was off are caught up on the next boot. The schedule is validated before anything is written.
+func CreateTimer(name, schedule, target string) error {
+	return CreateTimerContext(context.Background(), name, schedule, target)
+}
+
+// CreateTimerContext is like CreateTimer, but with a caller-supplied context.
+func CreateTimerContext(ctx context.Context, name, schedule, target string) (err error) {
+	if err := localOnly(ctx); err != nil {
+		return err
+	}
+
+	if name == "" || strings.ContainsRune(name, '/') || target == "" || strings.ContainsAny(target, "/\r\n") {
+		return ErrorInvalidTimer
+	}
+
+	if strings.ContainsAny(schedule, "\r\n") {
+		return fmt.Errorf("invalid calendar expression %q", schedule)
+	}
+
+	if !strings.HasSuffix(name, timerSuffix) {
+		name += timerSuffix
+	}
+
+	defer func() { audit(OperationInstall, name, err) }()
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	if err := validateCalendar(ctx, schedule); err != nil {
+		return err
+	}
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	dir, err := configDir(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	content := renderDropIn("Unit", [][2]string{{"Description", "Run " + target + " on schedule"}}) + "\n" +
+		renderDropIn("Timer", [][2]string{{"OnCalendar", schedule}, {"Unit", target}, {"Persistent", "true"}}) + "\n" +
+		renderDropIn("Install", [][2]string{{"WantedBy", "timers.target"}})
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil { //nolint:gosec // G306: unit configuration is world readable
+		return err
+	}
+
+	if err := conn.ReloadContext(ctx); err != nil {
+		return err
+	}
+
+	if _, _, err := conn.EnableUnitFilesContext(ctx, []string{name}, false, true); err != nil {
+		return err
+	}
+
+	return startUnit(ctx, conn, name)
+}
+
+// RemoveTimer stops, disables and removes a timer installed by CreateTimer. The unit it activates is left alone.
+func RemoveTimer(name string) error {
+	return RemoveTimerContext(context.Background(), name)
+}
+
+// RemoveTimerContext is like RemoveTimer, but with a caller-supplied context.
+func RemoveTimerContext(ctx context.Context, name string) error {
+	if !strings.HasSuffix(name, timerSuffix) {
+		name += timerSuffix
+	}
+
+	return uninstallUnit(ctx, name)
+}
@@ -0,0 +1,89 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTimers(t *testing.T) {
+	newFakeConnection(map[string]map[string]interface{}{
+		"casaos-backup.timer": {
+			"ActiveState":            "active",
+			"Unit":                   "casaos-backup.service",
+			"NextElapseUSecRealtime": uint64(1700000000000000),
+			"LastTriggerUSec":        uint64(0),
+			"TimersCalendar":         [][]interface{}{{"OnCalendar", "*-*-* 03:00:00", uint64(1700000000000000)}},
+		},
+		"casaos-backup.service": {"ActiveState": "inactive"},
+	}).use(t)
+
+	timers, err := ListTimers()
+	assert.NoError(t, err)
+	assert.Equal(t, []TimerInfo{{
+		Name:       "casaos-backup.timer",
+		Unit:       "casaos-backup.service",
+		Schedule:   "*-*-* 03:00:00",
+		NextElapse: time.UnixMicro(1700000000000000),
+		Running:    true,
+	}}, timers)
+
+	_, err = timerInfo(map[string]interface{}{
+		"Unit":                   "casaos-backup.service",
+		"NextElapseUSecRealtime": uint64(0),
+		"LastTriggerUSec":        uint64(0),
+		"TimersCalendar":         [][]interface{}{{"OnCalendar", []byte("daily"), uint64(0)}},
+	})
+	assert.ErrorIs(t, err, ErrorUnexpectedPropertyType)
+}
+
+func TestCreateTimer(t *testing.T) {
+	useTempDirs(t)
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos-backup.timer": {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	original := validateCalendar
+	validateCalendar = func(ctx context.Context, schedule string) error {
+		if schedule != "daily" {
+			return errors.New("invalid calendar expression")
+		}
+
+		return nil
+	}
+
+	t.Cleanup(func() { validateCalendar = original })
+
+	assert.Error(t, CreateTimer("casaos-backup", "every now and then", "casaos-backup.service"))
+	assert.ErrorIs(t, CreateTimer("casaos-backup", "daily", ""), ErrorInvalidTimer)
+	assert.Empty(t, conn.calls)
+
+	assert.NoError(t, CreateTimer("casaos-backup", "daily", "casaos-backup.service"))
+	assert.Equal(t, []string{"daemon-reload", "enable casaos-backup.timer", "start casaos-backup.timer"}, conn.calls)
+	assertFileContent(t, filepath.Join(unitConfigDir, "casaos-backup.timer"), `[Unit]
+Description=Run casaos-backup.service on schedule
+
+[Timer]
+OnCalendar=daily
+Unit=casaos-backup.service
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`)
+
+	conn.calls = nil
+
+	assert.NoError(t, RemoveTimer("casaos-backup"))
+	assert.Equal(t, []string{"stop casaos-backup.timer", "disable casaos-backup.timer", "daemon-reload"}, conn.calls)
+
+	_, err := os.Stat(filepath.Join(unitConfigDir, "casaos-backup.timer"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,70 @@
+package systemctl
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+var ErrorEmptyCommand = errors.New("command must not be empty")
+
+// StartTransientService runs the command as a transient service, like `systemd-run`, so it is supervised by
+// systemd, logs to the journal and is subject to resource limits, e.g.
+//
+//	err := systemctl.StartTransientService("casaos-fsck", []string{"fsck", "-n", "/dev/sda1"},
+//		dbus.PropDescription("Check /dev/sda1"),
+//		dbus.Property{Name: "MemoryMax", Value: godbus.MakeVariant(uint64(256 << 20))})
+//
+// The `.service` suffix is added to name if missing. A command without a path is looked up in $PATH. The service
+// disappears once the command exits, unless props say otherwise. With `Type=oneshot` among props, this returns
+// only after the command has exited.
+//
+// On a remote host, see WithRemoteHost, the command must be given with its absolute path, as $PATH is only
+// searched locally; ErrorRemoteHost is returned otherwise.
+func StartTransientService(name string, command []string, props ...dbus.Property) error {
+	return StartTransientServiceContext(context.Background(), name, command, props...)
+}
+
+// StartTransientServiceContext is like StartTransientService, but with a caller-supplied context.
+func StartTransientServiceContext(ctx context.Context, name string, command []string, props ...dbus.Property) (err error) {
+	if len(command) == 0 || command[0] == "" {
+		return ErrorEmptyCommand
+	}
+
+	name = serviceUnitName(name)
+
+	defer func() { audit(OperationStart, name, err) }()
+
+	path := command[0]
+	if !filepath.IsAbs(path) {
+		if err = localOnly(ctx); err != nil {
+			return err
+		}
+
+		if path, err = exec.LookPath(path); err != nil {
+			return err
+		}
+	}
+
+	properties := append([]dbus.Property{
+		dbus.PropExecStart(append([]string{path}, command[1:]...), false),
+	}, props...)
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return runJob(ctx, func(ctx context.Context, name string, mode string, ch chan<- string) (int, error) {
+		return conn.StartTransientUnitContext(ctx, name, mode, properties, ch)
+	}, name)
+}
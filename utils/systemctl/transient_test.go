@@ -0,0 +1,49 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartTransientService(t *testing.T) {
+	conn := newFakeConnection(map[string]map[string]interface{}{})
+	conn.use(t)
+
+	assert.ErrorIs(t, StartTransientService("casaos-fsck", nil), ErrorEmptyCommand)
+
+	assert.NoError(t, StartTransientService("casaos-fsck", []string{"/sbin/fsck", "-n", "/dev/sda1"}, dbus.PropDescription("Check /dev/sda1")))
+	assert.Equal(t, []string{"start-transient casaos-fsck.service"}, conn.calls)
+
+	unit := conn.units["casaos-fsck.service"]
+	assert.Equal(t, "Check /dev/sda1", unit["Description"])
+	assert.Equal(t, dbus.PropExecStart([]string{"/sbin/fsck", "-n", "/dev/sda1"}, false).Value.Value(), unit["ExecStart"])
+
+	// a relative command is resolved through $PATH
+	t.Setenv("PATH", "/nonexistent")
+	assert.Error(t, StartTransientService("casaos-migrate", []string{"casaos-migrate"}))
+}
+
+func TestStartTransientServiceRemote(t *testing.T) {
+	remote := &closableConnection{fakeConnection: newFakeConnection(map[string]map[string]interface{}{})}
+
+	original := dialRemoteHost
+	dialRemoteHost = func(ctx context.Context, host string) (poolableConnection, error) {
+		return remote, nil
+	}
+
+	t.Cleanup(func() {
+		Close()
+		dialRemoteHost = original
+	})
+
+	ctx := WithRemoteHost(context.Background(), "nas.local")
+
+	// an absolute path is passed on as is, as it is not looked up locally
+	assert.NoError(t, StartTransientServiceContext(ctx, "casaos-fsck", []string{"/sbin/fsck", "-n", "/dev/sda1"}))
+	assert.Equal(t, []string{"start-transient casaos-fsck.service"}, remote.calls)
+
+	assert.ErrorIs(t, StartTransientServiceContext(ctx, "casaos-fsck", []string{"fsck", "-n", "/dev/sda1"}), ErrorRemoteHost)
+}
@@ -0,0 +1,189 @@
+package systemctl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrorNoUnitFile      = errors.New("unit has no unit file")
+	ErrorInvalidUnitFile = errors.New("invalid unit file")
+)
+
+// ReadServiceUnit returns the content of the unit file of the unit, as located by its `FragmentPath`.
+// Drop-ins are not included.
+func ReadServiceUnit(name string) (string, error) {
+	return ReadServiceUnitContext(context.Background(), name)
+}
+
+// ReadServiceUnitContext is like ReadServiceUnit, but with a caller-supplied context.
+func ReadServiceUnitContext(ctx context.Context, name string) (string, error) {
+	if err := localOnly(ctx); err != nil {
+		return "", err
+	}
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	path, err := getStringProperty(ctx, conn, name, "FragmentPath")
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		return "", ErrorNoUnitFile
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// WriteServiceUnit writes the unit file of the unit under /etc/systemd/system and reloads systemd. Like
+// `systemctl edit --full`, a vendor unit file, e.g. under /usr/lib/systemd/system, is overridden by the copy rather
+// than changed, so package upgrades neither undo nor conflict with it. The content is checked to be well-formed
+// before anything is written, and written atomically, so the unit file is never left half written. Units that are
+// masked or that systemd could not load are refused with ErrorNotLoaded, see InstallService for new units.
+func WriteServiceUnit(name, content string) error {
+	return WriteServiceUnitContext(context.Background(), name, content)
+}
+
+// WriteServiceUnitContext is like WriteServiceUnit, but with a caller-supplied context.
+func WriteServiceUnitContext(ctx context.Context, name, content string) error {
+	if err := localOnly(ctx); err != nil {
+		return err
+	}
+
+	if name == "" || strings.ContainsRune(name, '/') {
+		return ErrorInvalidUnitName
+	}
+
+	if err := validateUnitFile(content); err != nil {
+		return err
+	}
+
+	// connect to systemd
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	// a masked unit's `FragmentPath` is /dev/null, which must not be written to
+	state, err := getStringProperty(ctx, conn, name, "LoadState")
+	if err != nil {
+		return err
+	}
+
+	if state != "loaded" {
+		return fmt.Errorf("%w: %s", ErrorNotLoaded, state)
+	}
+
+	dir, err := configDir(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(dir, name), []byte(content)); err != nil {
+		return err
+	}
+
+	return conn.ReloadContext(ctx)
+}
+
+// validateUnitFile checks that content consists of sections, `Key=Value` assignments, comments and blank lines,
+// as systemd expects. Whether the keys and values are meaningful is left to systemd.
+func validateUnitFile(content string) error {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+
+	section := false
+	continued := false
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// a line ending in a backslash continues on the next
+		if continued {
+			continued = strings.HasSuffix(line, "\\")
+			continue
+		}
+
+		switch {
+		case line == "", line[0] == '#', line[0] == ';':
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") || len(line) == 2 {
+				return fmt.Errorf("%w: line %d: malformed section header %q", ErrorInvalidUnitFile, number, line)
+			}
+
+			section = true
+		case !section:
+			return fmt.Errorf("%w: line %d: assignment outside of a section", ErrorInvalidUnitFile, number)
+		default:
+			key, _, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("%w: line %d: expected Key=Value, got %q", ErrorInvalidUnitFile, number, line)
+			}
+
+			continued = strings.HasSuffix(line, "\\")
+		}
+	}
+
+	return scanner.Err()
+}
+
+// writeFileAtomic writes the file by renaming a complete temporary copy over it.
+func writeFileAtomic(path string, content []byte) error {
+	dir := filepath.Dir(path)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name()) // no-op once renamed
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Chmod(0o644); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
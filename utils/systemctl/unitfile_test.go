@@ -0,0 +1,63 @@
+package systemctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceUnit(t *testing.T) {
+	useTempDirs(t)
+
+	vendorPath := filepath.Join(t.TempDir(), "casaos.service")
+	assert.NoError(t, os.WriteFile(vendorPath, []byte("[Service]\nExecStart=/usr/bin/casaos\n"), 0o644))
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service":     {"LoadState": "loaded", "FragmentPath": vendorPath},
+		"casaos-app.service": {"LoadState": "loaded", "FragmentPath": ""},
+		"nmbd.service":       {"LoadState": "masked", "FragmentPath": "/dev/null"},
+		"missing.service":    {"LoadState": "not-found", "FragmentPath": ""},
+	})
+	conn.use(t)
+
+	content, err := ReadServiceUnit("casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, "[Service]\nExecStart=/usr/bin/casaos\n", content)
+
+	_, err = ReadServiceUnit("casaos-app.service")
+	assert.ErrorIs(t, err, ErrorNoUnitFile)
+
+	assert.ErrorIs(t, WriteServiceUnit("casaos.service", "ExecStart=/usr/bin/casaos\n"), ErrorInvalidUnitFile)
+	assert.ErrorIs(t, WriteServiceUnit("../casaos.service", "[Service]\nExecStart=/usr/bin/casaos\n"), ErrorInvalidUnitName)
+	assert.ErrorIs(t, WriteServiceUnit("nmbd.service", "[Service]\nExecStart=/usr/sbin/nmbd\n"), ErrorNotLoaded)
+	assert.ErrorIs(t, WriteServiceUnit("missing.service", "[Service]\nExecStart=/usr/bin/missing\n"), ErrorNotLoaded)
+	assert.Empty(t, conn.calls)
+
+	// the vendor unit file is overridden by a copy, not changed
+	updated := "# managed by CasaOS\n[Unit]\nDescription=CasaOS\n\n[Service]\nExecStart=/usr/bin/casaos \\\n  --debug\nRestart=always\n"
+	assert.NoError(t, WriteServiceUnit("casaos.service", updated))
+	assertFileContent(t, filepath.Join(unitConfigDir, "casaos.service"), updated)
+	assertFileContent(t, vendorPath, "[Service]\nExecStart=/usr/bin/casaos\n")
+
+	assert.NoError(t, WriteServiceUnit("casaos-app.service", "[Service]\nExecStart=/usr/bin/app\n"))
+	assertFileContent(t, filepath.Join(unitConfigDir, "casaos-app.service"), "[Service]\nExecStart=/usr/bin/app\n")
+
+	assert.Equal(t, []string{"daemon-reload", "daemon-reload"}, conn.calls)
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(unitConfigDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestValidateUnitFile(t *testing.T) {
+	assert.NoError(t, validateUnitFile(""))
+	assert.NoError(t, validateUnitFile("; comment\n[Unit]\nDescription=\n"))
+	assert.ErrorIs(t, validateUnitFile("[Unit\nDescription=x\n"), ErrorInvalidUnitFile)
+	assert.ErrorIs(t, validateUnitFile("[]\n"), ErrorInvalidUnitFile)
+	assert.ErrorIs(t, validateUnitFile("[Service]\nExecStart /usr/bin/casaos\n"), ErrorInvalidUnitFile)
+	assert.ErrorIs(t, validateUnitFile("[Service]\n=value\n"), ErrorInvalidUnitFile)
+	assert.ErrorContains(t, validateUnitFile("[Service]\nUser=root\nbogus\n"), "line 3")
+}
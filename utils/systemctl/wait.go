@@ -0,0 +1,56 @@
+package systemctl
+
+import (
+	"context"
+	"time"
+)
+
+// settleInterval is how often WaitForServiceSettled polls the state of the unit.
+var settleInterval = 250 * time.Millisecond
+
+// WaitForServiceSettled waits until the unit is no longer `activating`, `deactivating` or `reloading`, and returns
+// the state it settled in, i.e. `active`, `inactive` or `failed`. This is how readiness of a `Type=notify` or
+// `Type=forking` service is resolved one way or the other. Only ctx bounds the whole wait, as a service may take
+// as long as its `TimeoutStartSec=` to settle, while the default timeout applies to each poll.
+func WaitForServiceSettled(ctx context.Context, name string) (string, error) {
+	// connect to systemd
+	dialCtx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	conn, err := newConnection(dialCtx)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	ticker := time.NewTicker(settleInterval)
+	defer ticker.Stop()
+
+	for {
+		state, err := settleState(ctx, conn, name)
+		if err != nil {
+			return "", err
+		}
+
+		switch state {
+		case "activating", "deactivating", "reloading":
+		default:
+			return state, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return state, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// settleState polls the `ActiveState` of the unit, for at most the default timeout.
+func settleState(ctx context.Context, conn connection, name string) (string, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	return getStringProperty(ctx, conn, name, "ActiveState")
+}
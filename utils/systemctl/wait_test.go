@@ -0,0 +1,83 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+)
+
+// steppingConnection advances the ActiveState of a unit through a list of states each time it is read.
+type steppingConnection struct {
+	*fakeConnection
+
+	states []string
+}
+
+func (c *steppingConnection) GetUnitPropertyContext(ctx context.Context, unit string, propertyName string) (*dbus.Property, error) {
+	if propertyName == "ActiveState" && len(c.states) > 0 {
+		c.units[unit]["ActiveState"] = c.states[0]
+		c.states = c.states[1:]
+	}
+
+	return c.fakeConnection.GetUnitPropertyContext(ctx, unit, propertyName)
+}
+
+func useSteppingConnection(t *testing.T, states ...string) {
+	conn := &steppingConnection{
+		fakeConnection: newFakeConnection(map[string]map[string]interface{}{
+			"casaos.service": {},
+		}),
+		states: states,
+	}
+
+	original, originalInterval := newConnection, settleInterval
+	newConnection = func(ctx context.Context) (connection, error) {
+		return conn, nil
+	}
+	settleInterval = time.Millisecond
+
+	t.Cleanup(func() { newConnection, settleInterval = original, originalInterval })
+}
+
+func TestWaitForServiceSettled(t *testing.T) {
+	useSteppingConnection(t, "activating", "activating", "reloading", "active")
+
+	state, err := WaitForServiceSettled(context.Background(), "casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, "active", state)
+}
+
+func TestWaitForServiceSettledFailed(t *testing.T) {
+	useSteppingConnection(t, "activating", "deactivating", "failed")
+
+	state, err := WaitForServiceSettled(context.Background(), "casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, "failed", state)
+}
+
+func TestWaitForServiceSettledTimeout(t *testing.T) {
+	useSteppingConnection(t, "activating")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	state, err := WaitForServiceSettled(ctx, "casaos.service")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "activating", state)
+}
+
+func TestWaitForServiceSettledSlow(t *testing.T) {
+	useSteppingConnection(t, "activating", "activating", "activating", "activating", "activating", "active")
+
+	// settling takes longer than the default timeout, which only bounds each poll
+	settleInterval = 10 * time.Millisecond
+	SetDefaultTimeout(20 * time.Millisecond)
+	t.Cleanup(func() { SetDefaultTimeout(0) })
+
+	state, err := WaitForServiceSettled(context.Background(), "casaos.service")
+	assert.NoError(t, err)
+	assert.Equal(t, "active", state)
+}
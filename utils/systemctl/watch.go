@@ -0,0 +1,168 @@
+package systemctl
+
+import (
+	"context"
+	"time"
+)
+
+// enablementPollInterval is how often WatchServices checks whether the watched units were enabled or disabled.
+var enablementPollInterval = 5 * time.Second
+
+// ServiceChange reports the state of a watched unit after it started or stopped, or was enabled or disabled.
+type ServiceChange struct {
+	Name    string
+	Running bool
+	Enabled bool
+}
+
+// WatchServices delivers the state of each of the named units once, then again whenever it starts or stops, or is
+// enabled or disabled, until ctx is canceled, after which the channel is closed.
+//
+// Starting and stopping is signaled by systemd as it happens. Enabling and disabling is not signaled per unit, so
+// it is polled for, and noticed within a few seconds.
+func WatchServices(ctx context.Context, names []string) (<-chan ServiceChange, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// subscribe first, so no change is missed while reading the initial states
+	events, err := SubscribeServiceEvents(ctx, "")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	states := make(map[string]ServiceChange, len(names))
+	for _, name := range names {
+		state, err := watchedState(ctx, name)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		states[name] = state
+	}
+
+	enabled := make(map[string]bool, len(names))
+	for name, state := range states {
+		enabled[name] = state.Enabled
+	}
+
+	// polled separately, so the events keep being drained while the units are read
+	enablements := pollEnablement(ctx, names, enabled)
+
+	changes := make(chan ServiceChange)
+
+	go func() {
+		defer close(changes)
+
+		// the poller must be done before the channel is closed
+		defer func() {
+			cancel()
+			for range enablements {
+			}
+		}()
+
+		send := func(change ServiceChange) bool {
+			select {
+			case changes <- change:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, name := range names {
+			if !send(states[name]) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				state, watched := states[event.Name]
+				if !watched || state.Running == (event.ActiveState == "active") {
+					continue
+				}
+
+				state.Running = !state.Running
+				states[event.Name] = state
+
+				if !send(state) {
+					return
+				}
+
+			case change := <-enablements:
+				state := states[change.Name]
+				state.Enabled = change.Enabled
+				states[change.Name] = state
+
+				if !send(state) {
+					return
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+func watchedState(ctx context.Context, name string) (ServiceChange, error) {
+	running, err := IsServiceRunningContext(ctx, name)
+	if err != nil {
+		return ServiceChange{}, err
+	}
+
+	enablement, err := GetServiceEnablementContext(ctx, name)
+	if err != nil {
+		return ServiceChange{}, err
+	}
+
+	return ServiceChange{Name: name, Running: running, Enabled: enablement.Enabled()}, nil
+}
+
+// pollEnablement reads whether each of the named units is enabled every enablementPollInterval, and delivers
+// those that changed since the last read, starting from enabled, until ctx is done, after which the channel is
+// closed.
+func pollEnablement(ctx context.Context, names []string, enabled map[string]bool) <-chan ServiceChange {
+	changes := make(chan ServiceChange)
+
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(enablementPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for _, name := range names {
+				// a unit that cannot be read right now keeps its last known state
+				enablement, err := GetServiceEnablementContext(ctx, name)
+				if err != nil || enablement.Enabled() == enabled[name] {
+					continue
+				}
+
+				enabled[name] = enablement.Enabled()
+
+				select {
+				case changes <- ServiceChange{Name: name, Enabled: enabled[name]}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
@@ -0,0 +1,135 @@
+package systemctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchServices(t *testing.T) {
+	sub := &fakeSubscription{closed: make(chan struct{})}
+
+	original, originalInterval := newSubscription, enablementPollInterval
+	newSubscription = func(ctx context.Context) (subscription, error) {
+		return sub, nil
+	}
+	enablementPollInterval = time.Millisecond
+
+	t.Cleanup(func() { newSubscription, enablementPollInterval = original, originalInterval })
+
+	conn := newFakeConnection(map[string]map[string]interface{}{
+		"casaos.service": {"ActiveState": "active", "UnitFileState": "enabled"},
+		"smbd.service":   {"ActiveState": "inactive", "UnitFileState": "disabled"},
+	})
+	conn.use(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := WatchServices(ctx, []string{"casaos.service", "smbd.service"})
+	assert.NoError(t, err)
+
+	// the initial states
+	assert.Equal(t, ServiceChange{Name: "casaos.service", Running: true, Enabled: true}, <-changes)
+	assert.Equal(t, ServiceChange{Name: "smbd.service", Running: false, Enabled: false}, <-changes)
+
+	// unwatched units and changes that keep a unit running are left out
+	sub.updates <- propertiesUpdate("nmbd.service", map[string]interface{}{"ActiveState": "active"})
+	sub.updates <- propertiesUpdate("casaos.service", map[string]interface{}{"ActiveState": "active", "SubState": "running"})
+	sub.updates <- propertiesUpdate("smbd.service", map[string]interface{}{"ActiveState": "active", "SubState": "running"})
+
+	assert.Equal(t, ServiceChange{Name: "smbd.service", Running: true, Enabled: false}, <-changes)
+
+	conn.mu.Lock()
+	conn.units["smbd.service"]["UnitFileState"] = "enabled"
+	conn.mu.Unlock()
+
+	assert.Equal(t, ServiceChange{Name: "smbd.service", Running: true, Enabled: true}, <-changes)
+
+	cancel()
+
+	select {
+	case <-sub.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed after cancel")
+	}
+
+	for range changes {
+	}
+
+	sub = &fakeSubscription{closed: make(chan struct{})}
+
+	_, err = WatchServices(context.Background(), []string{"nonexistent.service"})
+	assert.ErrorIs(t, err, errNoSuchUnit)
+}
+
+// blockingEnablementConnection blocks reading `UnitFileState` while blocked is set.
+type blockingEnablementConnection struct {
+	*fakeConnection
+
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingEnablementConnection) GetUnitPropertyContext(ctx context.Context, unit string, propertyName string) (*dbus.Property, error) {
+	if propertyName == "UnitFileState" {
+		select {
+		case <-c.blocked:
+			<-c.release
+		default:
+		}
+	}
+
+	return c.fakeConnection.GetUnitPropertyContext(ctx, unit, propertyName)
+}
+
+func TestWatchServicesDrainsWhilePolling(t *testing.T) {
+	sub := &fakeSubscription{closed: make(chan struct{})}
+
+	original, originalInterval, originalConnection := newSubscription, enablementPollInterval, newConnection
+	newSubscription = func(ctx context.Context) (subscription, error) {
+		return sub, nil
+	}
+	enablementPollInterval = time.Millisecond
+
+	conn := &blockingEnablementConnection{
+		fakeConnection: newFakeConnection(map[string]map[string]interface{}{
+			"casaos.service": {"ActiveState": "inactive", "UnitFileState": "enabled"},
+		}),
+		blocked: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	newConnection = func(ctx context.Context) (connection, error) {
+		return conn, nil
+	}
+
+	t.Cleanup(func() {
+		newSubscription, enablementPollInterval, newConnection = original, originalInterval, originalConnection
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := WatchServices(ctx, []string{"casaos.service"})
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceChange{Name: "casaos.service", Running: false, Enabled: true}, <-changes)
+
+	// while enablement is being read, more updates arrive than the subscription buffers
+	close(conn.blocked)
+	for i := 0; i < 300; i++ {
+		sub.updates <- propertiesUpdate("nmbd.service", map[string]interface{}{"ActiveState": "active"})
+	}
+
+	sub.updates <- propertiesUpdate("casaos.service", map[string]interface{}{"ActiveState": "active"})
+
+	assert.Equal(t, ServiceChange{Name: "casaos.service", Running: true, Enabled: true}, <-changes)
+
+	close(conn.release)
+	cancel()
+
+	for range changes {
+	}
+}